@@ -0,0 +1,362 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// Agent chooses actions on behalf of a player.
+type Agent interface {
+	ChooseAction(g *Game, playerID PlayerID) (*PlayerAction, error)
+}
+
+// PlayOut lets agents play the game until it is over.
+func PlayOut(g *Game, agents map[PlayerID]Agent) error {
+	for !g.IsGameOver() {
+		players := g.actingPlayers(g.State)
+		pas := make(PlayerActionSet, 0, len(players))
+		for _, p := range players {
+			agent, found := agents[p.ID]
+			if !found {
+				return fmt.Errorf("player (id: %d) agent not found", p.ID)
+			}
+			pa, err := agent.ChooseAction(g, p.ID)
+			if err != nil {
+				return err
+			}
+			pas = append(pas, pa)
+		}
+		if err := g.ApplyPlayerAction(pas); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Simulate plays games by agents with PlayOut and returns the number of wins of
+// each player. Each game is seeded by a source seeded by seed, so the results
+// are reproducible. Draws and games failed to be played count for nobody.
+func Simulate(settings *GameSettings, agents map[PlayerID]Agent, games int, seed int64) map[PlayerID]int {
+	r := rand.New(rand.NewSource(seed))
+	wins := make(map[PlayerID]int)
+	for i := 0; i < games; i++ {
+		g := NewGameWithSeed(settings, r.Int63())
+		if err := PlayOut(g, agents); err != nil {
+			continue
+		}
+		if p, ok := g.GetWinner(); ok {
+			wins[p.ID]++
+		}
+	}
+	return wins
+}
+
+// RandomAgent chooses an available action and a target uniformly at random.
+// The chosen action consumes MinThinkingTimeConsumption.
+type RandomAgent struct {
+	// Rand is used instead of Game.Rand if not nil.
+	Rand *rand.Rand
+}
+
+func (a *RandomAgent) ChooseAction(g *Game, playerID PlayerID) (*PlayerAction, error) {
+	r := a.Rand
+	if r == nil {
+		r = g.Rand
+	}
+	ps, found := g.State.PlayerStates.Get(playerID)
+	if !found {
+		return nil, fmt.Errorf("player (id: %d) state not found", playerID)
+	}
+	floor, ok := g.Settings.minConsumption(ps)
+	if !ok {
+		return nil, errors.New("minimum thinking time consumption is unaffordable")
+	}
+	actions := ps.AvailableActions()
+	if len(actions) == 0 {
+		return nil, errors.New("no available action")
+	}
+	targets := g.targets(playerID)
+	if len(targets) == 0 {
+		return nil, errors.New("no target")
+	}
+	return &PlayerAction{
+		PlayerID:                playerID,
+		TargetPlayerID:          targets[r.Intn(len(targets))],
+		Action:                  actions[r.Intn(len(actions))],
+		ThinkingTimeConsumption: floor,
+	}, nil
+}
+
+// WeightedRandomAgent chooses an available action at random weighted by its
+// level. While the player has at least LowThinkingTime, an attack of level L
+// weighs AttackWeight*L and a defence weighs DefenceWeight, so higher attacks
+// are preferred. Otherwise an attack weighs AttackWeight and a defence of level
+// L weighs DefenceWeight*L. Zero weights default to 1. Targets are chosen
+// uniformly.
+type WeightedRandomAgent struct {
+	// Rand is used instead of Game.Rand if not nil.
+	Rand            *rand.Rand
+	AttackWeight    float64
+	DefenceWeight   float64
+	LowThinkingTime time.Duration
+	// ThinkingTimeConsumption is reported in the chosen action, reduced to the
+	// remaining thinking time if the player cannot afford it and raised to
+	// MinThinkingTimeConsumption if less.
+	ThinkingTimeConsumption time.Duration
+}
+
+func (a *WeightedRandomAgent) ChooseAction(g *Game, playerID PlayerID) (*PlayerAction, error) {
+	r := a.Rand
+	if r == nil {
+		r = g.Rand
+	}
+	ps, found := g.State.PlayerStates.Get(playerID)
+	if !found {
+		return nil, fmt.Errorf("player (id: %d) state not found", playerID)
+	}
+	floor, ok := g.Settings.minConsumption(ps)
+	if !ok {
+		return nil, errors.New("minimum thinking time consumption is unaffordable")
+	}
+	actions := ps.AvailableActions()
+	if len(actions) == 0 {
+		return nil, errors.New("no available action")
+	}
+	targets := g.targets(playerID)
+	if len(targets) == 0 {
+		return nil, errors.New("no target")
+	}
+	attackWeight, defenceWeight := a.AttackWeight, a.DefenceWeight
+	if attackWeight == 0 {
+		attackWeight = 1
+	}
+	if defenceWeight == 0 {
+		defenceWeight = 1
+	}
+	ahead := ps.CanAfford(a.LowThinkingTime)
+	weights := make([]float64, len(actions))
+	var total float64
+	for i, action := range actions {
+		switch {
+		case action.Type == Attack && ahead:
+			weights[i] = attackWeight * float64(action.Level)
+		case action.Type == Attack:
+			weights[i] = attackWeight
+		case action.Type.defends() && ahead:
+			weights[i] = defenceWeight
+		default:
+			weights[i] = defenceWeight * float64(action.Level)
+		}
+		total += weights[i]
+	}
+	chosen := len(actions) - 1
+	for i, x := 0, r.Float64()*total; i < len(weights); i++ {
+		if x < weights[i] {
+			chosen = i
+			break
+		}
+		x -= weights[i]
+	}
+	consumption := a.ThinkingTimeConsumption
+	if !ps.CanAfford(consumption) {
+		consumption = ps.ThinkingTime
+	}
+	if consumption < floor {
+		consumption = floor
+	}
+	return &PlayerAction{
+		PlayerID:                playerID,
+		TargetPlayerID:          targets[r.Intn(len(targets))],
+		Action:                  actions[chosen],
+		ThinkingTimeConsumption: consumption,
+	}, nil
+}
+
+// targets returns the players whom playerID can target.
+func (g *Game) targets(playerID PlayerID) []PlayerID {
+	r := make([]PlayerID, 0, len(g.Settings.Players))
+	for _, p := range g.Settings.Players {
+		if ps, found := g.State.PlayerStates.Get(p.ID); found && ps.Status == Eliminated {
+			continue
+		}
+		if p.ID != playerID && (g.Settings.FriendlyFire || !g.Settings.isTeammate(playerID, p.ID)) {
+			r = append(r, p.ID)
+		}
+	}
+	return r
+}
+
+// LegalActions returns every distinct available action of playerID for every
+// valid target, plus Pass if AllowPass. Targets include playerID itself only
+// if AllowSelfTarget. The actions consume MinThinkingTimeConsumption. It
+// returns nil if the player cannot act now, e.g. the game is over, the player
+// waits for the others under AllPlayersEmpty or cannot afford
+// MinThinkingTimeConsumption.
+func (g *Game) LegalActions(playerID PlayerID) []PlayerAction {
+	if g.IsGameOver() {
+		return nil
+	}
+	if _, acts := g.actingPlayers(g.State).Get(playerID); !acts {
+		return nil
+	}
+	ps, found := g.State.PlayerStates.Get(playerID)
+	if !found || ps.Status != Playing {
+		return nil
+	}
+	consumption, ok := g.Settings.minConsumption(ps)
+	if !ok {
+		return nil
+	}
+	targets := g.targets(playerID)
+	if g.Settings.AllowSelfTarget {
+		targets = append(targets, playerID)
+	}
+	available := ps.AvailableActions()
+	actions := make(ActionList, 0, len(available)+1)
+	for _, a := range available {
+		if !actions.Contains(a) {
+			actions = append(actions, a)
+		}
+	}
+	if g.Settings.AllowPass {
+		actions = append(actions, Action{Type: Pass})
+	}
+	r := make([]PlayerAction, 0, len(actions)*len(targets))
+	for _, a := range actions {
+		for _, target := range targets {
+			r = append(r, PlayerAction{PlayerID: playerID, TargetPlayerID: target, Action: a, ThinkingTimeConsumption: consumption})
+		}
+	}
+	return r
+}
+
+// MinimaxAgent searches the game tree and chooses the action which maximizes
+// its points relative to the best opponent, assuming that the opponents choose
+// the worst actions for it. Every action consumes MinThinkingTimeConsumption
+// during the search.
+type MinimaxAgent struct {
+	// Depth is the number of action sets to look ahead. It defaults to 1.
+	Depth int
+	// ThinkingTimeConsumption is reported in the chosen action. It is raised
+	// to MinThinkingTimeConsumption if less.
+	ThinkingTimeConsumption time.Duration
+}
+
+func (a *MinimaxAgent) ChooseAction(g *Game, playerID PlayerID) (*PlayerAction, error) {
+	if _, found := g.State.PlayerStates.Get(playerID); !found {
+		return nil, fmt.Errorf("player (id: %d) state not found", playerID)
+	}
+	depth := a.Depth
+	if depth < 1 {
+		depth = 1
+	}
+	best, _ := a.search(g, g.State, playerID, depth)
+	if best == nil {
+		return nil, errors.New("no available action")
+	}
+	pa := *best
+	if a.ThinkingTimeConsumption > pa.ThinkingTimeConsumption {
+		pa.ThinkingTimeConsumption = a.ThinkingTimeConsumption
+	}
+	return &pa, nil
+}
+
+// search returns the best action of playerID in state and its value.
+func (a *MinimaxAgent) search(g *Game, state *GameState, playerID PlayerID, depth int) (*PlayerAction, int32) {
+	if depth == 0 || state.IsGameOver() {
+		return nil, evaluate(state, playerID)
+	}
+	var (
+		best      *PlayerAction
+		bestValue int32
+	)
+	for _, own := range candidateActions(g, state, playerID) {
+		var (
+			worst   int32
+			checked bool
+		)
+		for _, others := range opponentActionSets(g, state, playerID) {
+			next, _, err := g.nextState(state, append(PlayerActionSet{own}, others...))
+			if err != nil {
+				continue
+			}
+			_, v := a.search(g, next, playerID, depth-1)
+			if !checked || v < worst {
+				worst, checked = v, true
+			}
+			if best != nil && worst <= bestValue {
+				break
+			}
+		}
+		if checked && (best == nil || worst > bestValue) {
+			best, bestValue = own, worst
+		}
+	}
+	if best == nil {
+		return nil, evaluate(state, playerID)
+	}
+	return best, bestValue
+}
+
+// evaluate returns the points of playerID minus the best points of the others.
+func evaluate(state *GameState, playerID PlayerID) int32 {
+	var own, other int32
+	first := true
+	for _, ps := range state.PlayerStates {
+		if ps.PlayerID == playerID {
+			own = ps.Points
+		} else if first || ps.Points > other {
+			other, first = ps.Points, false
+		}
+	}
+	return own - other
+}
+
+// candidateActions returns the distinct actions of playerID in state, which
+// consume MinThinkingTimeConsumption. It returns nil if the player cannot
+// afford it.
+func candidateActions(g *Game, state *GameState, playerID PlayerID) PlayerActionSet {
+	ps, found := state.PlayerStates.Get(playerID)
+	if !found {
+		return nil
+	}
+	consumption, ok := g.Settings.minConsumption(ps)
+	if !ok {
+		return nil
+	}
+	var r PlayerActionSet
+	actions := ps.AvailableActions()
+	seen := make(map[Action]bool, len(actions))
+	for _, action := range actions {
+		if seen[action] {
+			continue
+		}
+		seen[action] = true
+		for _, target := range g.targets(playerID) {
+			r = append(r, &PlayerAction{PlayerID: playerID, TargetPlayerID: target, Action: action, ThinkingTimeConsumption: consumption})
+		}
+	}
+	return r
+}
+
+// opponentActionSets returns every combination of the candidate actions of
+// the players other than playerID.
+func opponentActionSets(g *Game, state *GameState, playerID PlayerID) []PlayerActionSet {
+	r := []PlayerActionSet{{}}
+	for _, p := range g.actingPlayers(state) {
+		if p.ID == playerID {
+			continue
+		}
+		candidates := candidateActions(g, state, p.ID)
+		next := make([]PlayerActionSet, 0, len(r)*len(candidates))
+		for _, pas := range r {
+			for _, pa := range candidates {
+				next = append(next, append(pas[:len(pas):len(pas)], pa))
+			}
+		}
+		r = next
+	}
+	return r
+}
@@ -0,0 +1,195 @@
+package core
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPlayOut(t *testing.T) {
+	settings := newTestSettings()
+	settings.TotalGames = 3
+	settings.Players = append(settings.Players, &Player{ID: 3, Name: "P3"})
+	g := NewGameWithSeed(settings, 7)
+	agents := map[PlayerID]Agent{1: &RandomAgent{}, 2: &RandomAgent{}, 3: &RandomAgent{}}
+	if err := PlayOut(g, agents); err != nil {
+		t.Fatal(err)
+	}
+	if !g.IsGameOver() || len(g.ActionLogs) != 3*len(settings.Actions) {
+		t.Fatalf("unexpected game: %d, %d", g.State.GameNum, len(g.ActionLogs))
+	}
+	for _, pas := range g.ActionLogs {
+		for _, pa := range pas {
+			if pa.PlayerID == pa.TargetPlayerID {
+				t.Fatalf("self target: %+v", pa)
+			}
+		}
+	}
+
+	replayed := NewGameWithSeed(settings, 7)
+	if err := PlayOut(replayed, agents); err != nil {
+		t.Fatal(err)
+	}
+	assertPoints(t, replayed, g.State.PlayerStates[0].Points, g.State.PlayerStates[1].Points, g.State.PlayerStates[2].Points)
+
+	floored := *settings
+	floored.MinThinkingTimeConsumption = time.Second
+	g = NewGameWithSeed(&floored, 7)
+	if err := PlayOut(g, agents); err != nil {
+		t.Fatal(err)
+	}
+	weighted := map[PlayerID]Agent{1: &WeightedRandomAgent{}, 2: &WeightedRandomAgent{}, 3: &WeightedRandomAgent{}}
+	if err := PlayOut(NewGameWithSeed(&floored, 7), weighted); err != nil {
+		t.Fatal(err)
+	}
+	if wins := Simulate(&floored, agents, 10, 3); len(wins) == 0 {
+		t.Error("no game was won under the floor")
+	}
+
+	delete(agents, 3)
+	if err := PlayOut(NewGame(settings), agents); err == nil {
+		t.Fatal("played out without agent")
+	}
+}
+
+func TestMinimaxAgent(t *testing.T) {
+	settings := newTestSettings()
+	settings.Actions = ActionList{{Attack, 1}, {Attack, 2}, {Defence, 1}, {Defence, 2}}
+	agent := &MinimaxAgent{Depth: len(settings.Actions)}
+	g := NewGame(settings)
+	_, guaranteed := agent.search(g, g.State, 1, agent.Depth)
+
+	before := g.State.Clone()
+	if _, err := agent.ChooseAction(g, 1); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(g.State, before) || len(g.ActionLogs) != 0 {
+		t.Fatal("game was mutated")
+	}
+
+	floored := *settings
+	floored.MinThinkingTimeConsumption = time.Second
+	g = NewGame(&floored)
+	pa, err := agent.ChooseAction(g, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pa.ThinkingTimeConsumption != time.Second {
+		t.Errorf("unexpected consumption: %v", pa.ThinkingTimeConsumption)
+	}
+
+	// With a uniformly random opponent every order of a fixed action pool is
+	// equally good in expectation, so the agent cannot always win. It must
+	// not fall below the value it guarantees, though.
+	for seed := int64(0); seed < 30; seed++ {
+		g := NewGameWithSeed(settings, seed)
+		if err := PlayOut(g, map[PlayerID]Agent{1: agent, 2: &RandomAgent{}}); err != nil {
+			t.Fatal(err)
+		}
+		if v := evaluate(g.State, 1); v < guaranteed {
+			t.Fatalf("seed %d: points difference %d is below the guaranteed %d", seed, v, guaranteed)
+		}
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	settings := newTestSettings()
+	agents := map[PlayerID]Agent{1: &RandomAgent{}, 2: &RandomAgent{}}
+	const games = 1000
+	wins := Simulate(settings, agents, games, 3)
+	if !reflect.DeepEqual(wins, Simulate(settings, agents, games, 3)) {
+		t.Fatal("simulation should be reproducible")
+	}
+	total := wins[1] + wins[2]
+	if total == 0 || total > games {
+		t.Fatalf("unexpected wins: %v", wins)
+	}
+	if rate := float64(wins[1]) / float64(total); rate < 0.4 || 0.6 < rate {
+		t.Errorf("unbalanced wins: %v", wins)
+	}
+}
+
+func TestWeightedRandomAgent(t *testing.T) {
+	g := NewGame(newTestSettings())
+	count := func(agent *WeightedRandomAgent) (attacks, defences int) {
+		for i := 0; i < 1000; i++ {
+			pa, err := agent.ChooseAction(g, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := g.ValidateActions(PlayerActionSet{pa}); err != nil {
+				t.Fatal(err)
+			}
+			if pa.ThinkingTimeConsumption > g.State.PlayerStates[0].ThinkingTime {
+				t.Fatalf("unaffordable consumption: %v", pa.ThinkingTimeConsumption)
+			}
+			if pa.Action.Type == Attack {
+				attacks++
+			} else {
+				defences++
+			}
+		}
+		return
+	}
+	agent := &WeightedRandomAgent{
+		Rand:                    rand.New(rand.NewSource(1)),
+		LowThinkingTime:         5 * time.Second,
+		ThinkingTimeConsumption: time.Minute,
+	}
+	if attacks, defences := count(agent); attacks <= defences {
+		t.Errorf("attacks should be preferred with a big clock: %d, %d", attacks, defences)
+	}
+	agent.LowThinkingTime = time.Minute
+	if attacks, defences := count(agent); attacks >= defences {
+		t.Errorf("defences should be preferred with a small clock: %d, %d", attacks, defences)
+	}
+}
+
+func TestLegalActions(t *testing.T) {
+	settings := newTestSettings()
+	settings.Players = append(settings.Players, &Player{ID: 3, Name: "P3"})
+	g := NewGame(settings)
+	if n := len(g.LegalActions(1)); n != 6*2 {
+		t.Fatalf("unexpected number of legal actions: %d", n)
+	}
+	for _, pa := range g.LegalActions(1) {
+		if err := g.ValidateActions(PlayerActionSet{&pa}); err != nil {
+			t.Fatalf("%v: %v", pa, err)
+		}
+	}
+	err := g.ApplyPlayerAction(PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 1}},
+		{PlayerID: 2, TargetPlayerID: 3, Action: Action{Attack, 1}},
+		{PlayerID: 3, TargetPlayerID: 1, Action: Action{Attack, 1}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(g.LegalActions(1)); n != 5*2 {
+		t.Errorf("unexpected number of legal actions: %d", n)
+	}
+	g.Settings.AllowSelfTarget = true
+	if n := len(g.LegalActions(1)); n != 5*3 {
+		t.Errorf("unexpected number of legal actions: %d", n)
+	}
+	if err := g.Forfeit(1); err != nil {
+		t.Fatal(err)
+	}
+	if r := g.LegalActions(2); r != nil {
+		t.Errorf("no action should be legal after the game is over: %v", r)
+	}
+
+	settings = newTestSettings()
+	settings.MinThinkingTimeConsumption = 2 * time.Second
+	settings.ThinkingTimeIncrement = 0
+	g = NewGame(settings)
+	pa1, pa2 := g.LegalActions(1)[0], g.LegalActions(2)[0]
+	if err := g.CanApplyPlayerAction(PlayerActionSet{&pa1, &pa2}); err != nil {
+		t.Fatalf("legal actions rejected: %v", err)
+	}
+	g.State.PlayerStates[0].ThinkingTime = time.Second
+	if r := g.LegalActions(1); r != nil {
+		t.Errorf("no action should be legal under the floor: %v", r)
+	}
+}
@@ -0,0 +1,301 @@
+package core
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// binaryFormatVersion is the first byte of MarshalBinary.
+const binaryFormatVersion = 1
+
+// MarshalBinary encodes the game into a compact binary form for slow networks.
+// Integers are varints, durations are in milliseconds as in JSON, and an
+// action is packed into Level<<3|Type, which is a byte for levels below 16.
+// Settings are embedded as JSON since they are sent once per game.
+// Rand and observers are not encoded as in EncodeGob.
+func (g *Game) MarshalBinary() ([]byte, error) {
+	if g.State == nil {
+		return nil, errors.New("state not found")
+	}
+	settings, err := json.Marshal(g.Settings)
+	if err != nil {
+		return nil, err
+	}
+	e := &binaryEncoder{buf: []byte{binaryFormatVersion}}
+	e.uvarint(uint64(len(settings)))
+	e.buf = append(e.buf, settings...)
+	e.varint(g.Seed)
+	e.uvarint(uint64(g.State.GameNum))
+	e.uvarint(uint64(g.State.Turns))
+	e.uvarint(uint64(len(g.State.PlayerStates)))
+	for _, ps := range g.State.PlayerStates {
+		e.uvarint(uint64(ps.PlayerID))
+		e.varint(int64(ps.Status))
+		e.varint(int64(ps.Points))
+		e.duration(ps.ThinkingTime)
+		e.bool(ps.Unlimited)
+		e.varint(int64(ps.ByoYomiPeriods))
+		e.varint(int64(ps.Streak))
+		e.uvarint(uint64(len(ps.Actions)))
+		for _, a := range ps.Actions {
+			e.action(a)
+		}
+		e.uvarint(uint64(len(ps.UsedActions)))
+		for _, a := range ps.UsedActions {
+			e.action(a)
+		}
+		e.uvarint(uint64(len(ps.Cooldowns)))
+		for _, c := range ps.Cooldowns {
+			e.action(c.Action)
+			e.uvarint(uint64(c.Rounds))
+		}
+		e.uvarint(uint64(len(ps.PointsHistory)))
+		for _, p := range ps.PointsHistory {
+			e.varint(int64(p))
+		}
+	}
+	ids := make([]PlayerID, 0, len(g.State.RoundWins))
+	for id := range g.State.RoundWins {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	e.uvarint(uint64(len(ids)))
+	for _, id := range ids {
+		e.uvarint(uint64(id))
+		e.varint(int64(g.State.RoundWins[id]))
+	}
+	e.uvarint(uint64(len(g.ActionLogs)))
+	for _, pas := range g.ActionLogs {
+		e.playerActionSet(pas)
+	}
+	e.playerActionSet(g.PendingActions)
+	e.uvarint(uint64(len(g.Events)))
+	for _, ev := range g.Events {
+		e.varint(int64(ev.Type))
+		e.varint(int64(ev.Turn))
+		e.uvarint(uint64(ev.GameNum))
+		e.uvarint(uint64(ev.PlayerID))
+		e.uvarint(uint64(ev.TargetPlayerID))
+		e.varint(int64(ev.Delta))
+	}
+	return e.buf, nil
+}
+
+// UnmarshalBinary decodes data written by MarshalBinary into g.
+// Settings are migrated and Rand is reset to Seed as in LoadGame.
+func (g *Game) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 || data[0] != binaryFormatVersion {
+		return errors.New("unknown binary format")
+	}
+	d := &binaryDecoder{buf: data[1:]}
+	settings := d.bytes(int(d.uvarint()))
+	if d.err != nil {
+		return fmt.Errorf("invalid binary game: %v", d.err)
+	}
+	var r Game
+	if err := json.Unmarshal(settings, &r.Settings); err != nil {
+		return err
+	}
+	r.Seed = d.varint()
+	r.State = &GameState{GameNum: uint32(d.uvarint()), Turns: uint32(d.uvarint())}
+	n := d.length()
+	r.State.PlayerStates = make(PlayerStateSet, 0, n)
+	for i := 0; i < n; i++ {
+		ps := &PlayerState{
+			PlayerID:       PlayerID(d.uvarint()),
+			Status:         PlayerStatus(d.varint()),
+			Points:         int32(d.varint()),
+			ThinkingTime:   d.duration(),
+			Unlimited:      d.bool(),
+			ByoYomiPeriods: int(d.varint()),
+			Streak:         int(d.varint()),
+		}
+		ps.Actions = make(ActionList, d.length())
+		for j := range ps.Actions {
+			ps.Actions[j] = d.action()
+		}
+		if m := d.length(); m > 0 {
+			ps.UsedActions = make(ActionList, m)
+			for j := range ps.UsedActions {
+				ps.UsedActions[j] = d.action()
+			}
+		}
+		if m := d.length(); m > 0 {
+			ps.Cooldowns = make([]Cooldown, m)
+			for j := range ps.Cooldowns {
+				ps.Cooldowns[j] = Cooldown{Action: d.action(), Rounds: uint32(d.uvarint())}
+			}
+		}
+		if m := d.length(); m > 0 {
+			ps.PointsHistory = make([]int32, m)
+			for j := range ps.PointsHistory {
+				ps.PointsHistory[j] = int32(d.varint())
+			}
+		}
+		r.State.PlayerStates = append(r.State.PlayerStates, ps)
+	}
+	if n := d.length(); n > 0 {
+		r.State.RoundWins = make(map[PlayerID]int, n)
+		for i := 0; i < n; i++ {
+			id := PlayerID(d.uvarint())
+			r.State.RoundWins[id] = int(d.varint())
+		}
+	}
+	r.ActionLogs = make([]PlayerActionSet, d.length())
+	for i := range r.ActionLogs {
+		r.ActionLogs[i] = d.playerActionSet()
+	}
+	if pending := d.playerActionSet(); len(pending) > 0 {
+		r.PendingActions = pending
+	}
+	r.Events = make([]GameEvent, d.length())
+	for i := range r.Events {
+		r.Events[i] = GameEvent{
+			Type:           GameEventType(d.varint()),
+			Turn:           int(d.varint()),
+			GameNum:        uint32(d.uvarint()),
+			PlayerID:       PlayerID(d.uvarint()),
+			TargetPlayerID: PlayerID(d.uvarint()),
+			Delta:          int32(d.varint()),
+		}
+	}
+	if d.err == nil && len(d.buf) > 0 {
+		d.err = errors.New("trailing data")
+	}
+	if d.err != nil {
+		return fmt.Errorf("invalid binary game: %v", d.err)
+	}
+	if err := r.validateLoaded(); err != nil {
+		return fmt.Errorf("invalid binary game: %v", err)
+	}
+	g.Settings = r.Settings
+	g.ActionLogs = r.ActionLogs
+	g.State = r.State
+	g.Events = r.Events
+	g.PendingActions = r.PendingActions
+	g.Seed = r.Seed
+	g.Rand = r.Rand
+	return nil
+}
+
+type binaryEncoder struct {
+	buf []byte
+}
+
+func (e *binaryEncoder) uvarint(x uint64) {
+	e.buf = binary.AppendUvarint(e.buf, x)
+}
+
+func (e *binaryEncoder) varint(x int64) {
+	e.buf = binary.AppendVarint(e.buf, x)
+}
+
+func (e *binaryEncoder) bool(b bool) {
+	if b {
+		e.buf = append(e.buf, 1)
+	} else {
+		e.buf = append(e.buf, 0)
+	}
+}
+
+func (e *binaryEncoder) duration(d time.Duration) {
+	e.varint(*toMillis(d))
+}
+
+func (e *binaryEncoder) action(a Action) {
+	e.uvarint(uint64(uint8(a.Level))<<3 | uint64(uint8(a.Type)&7))
+}
+
+func (e *binaryEncoder) playerActionSet(pas PlayerActionSet) {
+	e.uvarint(uint64(len(pas)))
+	for _, pa := range pas {
+		e.uvarint(uint64(pa.PlayerID))
+		e.uvarint(uint64(pa.TargetPlayerID))
+		e.action(pa.Action)
+		e.duration(pa.ThinkingTimeConsumption)
+	}
+}
+
+// binaryDecoder keeps the first error and returns zero values after it.
+type binaryDecoder struct {
+	buf []byte
+	err error
+}
+
+func (d *binaryDecoder) fail(err error) {
+	if d.err == nil {
+		d.err = err
+	}
+	d.buf = nil
+}
+
+func (d *binaryDecoder) uvarint() uint64 {
+	x, n := binary.Uvarint(d.buf)
+	if n <= 0 {
+		d.fail(errors.New("invalid uvarint"))
+		return 0
+	}
+	d.buf = d.buf[n:]
+	return x
+}
+
+func (d *binaryDecoder) varint() int64 {
+	x, n := binary.Varint(d.buf)
+	if n <= 0 {
+		d.fail(errors.New("invalid varint"))
+		return 0
+	}
+	d.buf = d.buf[n:]
+	return x
+}
+
+// length reads the length of a list, which cannot exceed the remaining bytes.
+func (d *binaryDecoder) length() int {
+	n := d.uvarint()
+	if n > uint64(len(d.buf)) {
+		d.fail(errors.New("invalid length"))
+		return 0
+	}
+	return int(n)
+}
+
+func (d *binaryDecoder) bytes(n int) []byte {
+	if n < 0 || n > len(d.buf) {
+		d.fail(errors.New("unexpected end of data"))
+		return nil
+	}
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+	return b
+}
+
+func (d *binaryDecoder) bool() bool {
+	b := d.bytes(1)
+	return len(b) == 1 && b[0] != 0
+}
+
+func (d *binaryDecoder) duration() time.Duration {
+	return time.Duration(d.varint()) * time.Millisecond
+}
+
+func (d *binaryDecoder) action() Action {
+	x := d.uvarint()
+	return Action{Type: ActionType(x & 7), Level: ActionLevel(int8(uint8(x >> 3)))}
+}
+
+func (d *binaryDecoder) playerActionSet() PlayerActionSet {
+	pas := make(PlayerActionSet, d.length())
+	for i := range pas {
+		pas[i] = &PlayerAction{
+			PlayerID:                PlayerID(d.uvarint()),
+			TargetPlayerID:          PlayerID(d.uvarint()),
+			Action:                  d.action(),
+			ThinkingTimeConsumption: d.duration(),
+		}
+	}
+	return pas
+}
@@ -0,0 +1,81 @@
+package core
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMarshalBinary(t *testing.T) {
+	settings := newTestSettings()
+	settings.TotalGames = 2
+	g := NewGameWithSeed(settings, 42)
+	if err := PlayOut(g, map[PlayerID]Agent{
+		1: &WeightedRandomAgent{ThinkingTimeConsumption: 1500 * time.Millisecond},
+		2: &RandomAgent{},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := g.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Game
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if d := g.Diff(&decoded); len(d) != 0 {
+		t.Fatalf("unexpected differences: %v", d)
+	}
+	if !reflect.DeepEqual(g.ActionLogs, decoded.ActionLogs) || !reflect.DeepEqual(g.Events, decoded.Events) {
+		t.Fatal("logs or events differ")
+	}
+	if decoded.Seed != 42 || decoded.Rand == nil || decoded.Settings.TotalGames != 2 {
+		t.Fatalf("unexpected game: %+v", decoded)
+	}
+	js, err := json.Marshal(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data)*2 > len(js) {
+		t.Errorf("binary should be less than half of JSON: %d, %d", len(data), len(js))
+	}
+
+	for i, invalid := range [][]byte{nil, {0}, data[:len(data)-1], append(data, 0)} {
+		if err := new(Game).UnmarshalBinary(invalid); err == nil {
+			t.Errorf("%d: invalid data decoded", i)
+		}
+	}
+}
+
+func TestMarshalBinarySubMillisecond(t *testing.T) {
+	g := NewGame(newTestSettings())
+	err := g.ApplyPlayerAction(PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 1}, ThinkingTimeConsumption: 500 * time.Microsecond},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 1}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := g.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Game
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	js, err := json.Marshal(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fromJSON Game
+	if err := json.Unmarshal(js, &fromJSON); err != nil {
+		t.Fatal(err)
+	}
+	got, want := decoded.ActionLogs[0][0].ThinkingTimeConsumption, fromJSON.ActionLogs[0][0].ThinkingTimeConsumption
+	if got != time.Millisecond || got != want {
+		t.Errorf("unexpected consumption: %v, JSON: %v", got, want)
+	}
+}
@@ -0,0 +1,97 @@
+package core
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+)
+
+// HashAction returns the SHA-256 hash of salt and pa to be committed before
+// pa is revealed. The salt must be secret and random so that the action
+// cannot be guessed from the hash.
+func HashAction(pa *PlayerAction, salt []byte) []byte {
+	h := sha256.New()
+	h.Write(salt)
+	binary.Write(h, binary.BigEndian, []int64{
+		int64(pa.PlayerID),
+		int64(pa.TargetPlayerID),
+		int64(pa.Action.Type),
+		int64(pa.Action.Level),
+		int64(pa.ThinkingTimeConsumption),
+	})
+	return h.Sum(nil)
+}
+
+// CommitReveal resolves action sets of Game in two phases for untrusted
+// peers. Every acting player commits the hash of its action by HashAction
+// first, and then reveals the action with the salt. Once all of them are
+// revealed, the actions are applied by ApplyPlayerAction.
+type CommitReveal struct {
+	Game    *Game
+	commits map[PlayerID][]byte
+	reveals PlayerActionSet
+}
+
+func NewCommitReveal(g *Game) *CommitReveal {
+	return &CommitReveal{Game: g, commits: make(map[PlayerID][]byte)}
+}
+
+// Commit stores the hash of the action of playerID.
+func (cr *CommitReveal) Commit(playerID PlayerID, hash []byte) error {
+	if cr.Game.IsGameOver() {
+		return ErrGameOver
+	}
+	if _, acts := cr.Game.actingPlayers(cr.Game.State).Get(playerID); !acts {
+		return &PlayerNotFoundError{PlayerID: playerID}
+	}
+	if _, found := cr.commits[playerID]; found {
+		return fmt.Errorf("player (id: %d) already committed", playerID)
+	}
+	cr.commits[playerID] = append([]byte(nil), hash...)
+	return nil
+}
+
+// PendingCommits returns the acting players who have not committed yet.
+// Reveals are accepted only after it gets empty.
+func (cr *CommitReveal) PendingCommits() []PlayerID {
+	var r []PlayerID
+	for _, p := range cr.Game.actingPlayers(cr.Game.State) {
+		if _, found := cr.commits[p.ID]; !found {
+			r = append(r, p.ID)
+		}
+	}
+	return r
+}
+
+// Reveal verifies pa against the commit of its player and buffers it.
+// Once every acting player revealed, the actions are applied and resolved is
+// true. The commits are cleared even if applying fails so that the players can
+// commit again.
+func (cr *CommitReveal) Reveal(pa *PlayerAction, salt []byte) (resolved bool, err error) {
+	if len(cr.PendingCommits()) > 0 {
+		return false, fmt.Errorf("commits are pending: %v", cr.PendingCommits())
+	}
+	commit, found := cr.commits[pa.PlayerID]
+	if !found {
+		return false, &PlayerNotFoundError{PlayerID: pa.PlayerID}
+	}
+	if _, found := cr.reveals.Get(pa.PlayerID); found {
+		return false, fmt.Errorf("player (id: %d) already revealed", pa.PlayerID)
+	}
+	if !bytes.Equal(commit, HashAction(pa, salt)) {
+		return false, fmt.Errorf("player (id: %d): %w", pa.PlayerID, ErrRevealMismatch)
+	}
+	copied := *pa
+	cr.reveals = append(cr.reveals, &copied)
+	if len(cr.reveals) < len(cr.commits) {
+		return false, nil
+	}
+	reveals := cr.reveals
+	cr.commits = make(map[PlayerID][]byte)
+	cr.reveals = nil
+	if err := cr.Game.ApplyPlayerAction(reveals); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,47 @@
+package core
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCommitReveal(t *testing.T) {
+	g := NewGame(newTestSettings())
+	cr := NewCommitReveal(g)
+	pa1 := &PlayerAction{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 3}}
+	pa2 := &PlayerAction{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 1}}
+	salt1, salt2 := []byte("salt1"), []byte("salt2")
+	if err := cr.Commit(1, HashAction(pa1, salt1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := cr.Commit(1, HashAction(pa1, salt1)); err == nil {
+		t.Fatal("committed twice")
+	}
+	if _, err := cr.Reveal(pa1, salt1); err == nil {
+		t.Fatal("revealed before every player committed")
+	}
+	if err := cr.Commit(2, HashAction(pa2, salt2)); err != nil {
+		t.Fatal(err)
+	}
+	tampered := *pa1
+	tampered.Action = Action{Attack, 2}
+	if _, err := cr.Reveal(&tampered, salt1); !errors.Is(err, ErrRevealMismatch) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := cr.Reveal(pa1, salt2); !errors.Is(err, ErrRevealMismatch) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resolved, err := cr.Reveal(pa1, salt1); resolved || err != nil {
+		t.Fatalf("unexpected result: %v, %v", resolved, err)
+	}
+	if len(g.ActionLogs) != 0 {
+		t.Fatal("resolved before every player revealed")
+	}
+	if resolved, err := cr.Reveal(pa2, salt2); !resolved || err != nil {
+		t.Fatalf("unexpected result: %v, %v", resolved, err)
+	}
+	if len(g.ActionLogs) != 1 || len(cr.PendingCommits()) != 2 {
+		t.Fatalf("unexpected game: %d, %v", len(g.ActionLogs), cr.PendingCommits())
+	}
+	assertPoints(t, g, 2, 0)
+}
@@ -1,48 +1,236 @@
 package core
 
 import (
+	"encoding/binary"
 	"errors"
 	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
-const Version = "0.1.0"
+const Version = "0.2.0"
 
 type ActionType int8
 
 const (
 	Attack ActionType = iota
 	Defence
+	// Forfeit is only used in ActionLogs to record Game.Forfeit.
+	Forfeit
+	// Pass is allowed only under AllowPass. It is not taken from the pool and
+	// scores nothing, and attacks against it score as against no defence.
+	// TargetPlayerID and Level are ignored.
+	Pass
+	// Swap exchanges the points of the player and its target. It is taken
+	// from the pool as Attack and Defence and follows the target rules of
+	// Attack. Swaps are applied in order after all the attacks of the action
+	// set are scored, so two players swapping with each other keep their
+	// points. An attack on a swapping player scores as against no defence.
+	Swap
+	// Counter is a defence which hurts the attacker when it exceeds the
+	// attack by CounterMargin or more. See DefaultScore.
+	Counter
+	// Eliminate is only used in ActionLogs to record Game.Eliminate.
+	Eliminate
+	// Timeout is only used in ActionLogs to record Game.Timeout.
+	Timeout
 )
 
+// defends returns true if t guards against attacks.
+func (t ActionType) defends() bool {
+	return t == Defence || t == Counter
+}
+
+// targetsPlayer returns true if t affects TargetPlayerID.
+func (t ActionType) targetsPlayer() bool {
+	return t == Attack || t == Swap
+}
+
+func (t ActionType) String() string {
+	switch t {
+	case Attack:
+		return "Attack"
+	case Defence:
+		return "Defence"
+	case Forfeit:
+		return "Forfeit"
+	case Pass:
+		return "Pass"
+	case Swap:
+		return "Swap"
+	case Counter:
+		return "Counter"
+	case Eliminate:
+		return "Eliminate"
+	case Timeout:
+		return "Timeout"
+	default:
+		return fmt.Sprintf("ActionType(%d)", int8(t))
+	}
+}
+
+// ParseActionType parses the name of an action type case-insensitively.
+// Integer values are also accepted for compatibility.
+func ParseActionType(s string) (ActionType, error) {
+	for _, t := range []ActionType{Attack, Defence, Forfeit, Pass, Swap, Counter, Eliminate, Timeout} {
+		if strings.EqualFold(s, t.String()) {
+			return t, nil
+		}
+	}
+	if n, err := strconv.ParseInt(s, 10, 8); err == nil {
+		return ActionType(n), nil
+	}
+	return 0, fmt.Errorf("invalid action type: %q", s)
+}
+
 type ActionLevel int8
 
+// The range of levels accepted by GameSettings.Validate.
+// It keeps the results of Sub within int8.
+const (
+	MinActionLevel ActionLevel = 1
+	MaxActionLevel ActionLevel = math.MaxInt8
+)
+
+// IsValid returns true if l is within [min, max].
+func (l ActionLevel) IsValid(min, max ActionLevel) bool {
+	return min <= l && l <= max
+}
+
 func (l ActionLevel) Sub(level ActionLevel) int8 {
 	return int8(l - level)
 }
 
+func (l ActionLevel) String() string {
+	return fmt.Sprintf("L%d", int8(l))
+}
+
 type Action struct {
 	Type  ActionType
 	Level ActionLevel
 }
 
+// String returns e.g. "Attack L2".
+func (a Action) String() string {
+	if a.Type == Forfeit || a.Type == Pass || a.Type == Eliminate || a.Type == Timeout {
+		return a.Type.String()
+	}
+	return a.Type.String() + " " + a.Level.String()
+}
+
 type ActionList []Action
 
-func (al ActionList) Remove(action Action) (ActionList, bool) {
+func (al ActionList) index(action Action) int {
 	for i, a := range al {
 		if a == action {
-			return append(al[:i], al[i+1:]...), true
+			return i
+		}
+	}
+	return -1
+}
+
+// Remove returns a new list without the first occurrence of action.
+// The receiver is never mutated.
+func (al ActionList) Remove(action Action) (ActionList, bool) {
+	i := al.index(action)
+	if i < 0 {
+		return al, false
+	}
+	r := make(ActionList, 0, len(al)-1)
+	r = append(r, al[:i]...)
+	return append(r, al[i+1:]...), true
+}
+
+func (al ActionList) Contains(action Action) bool {
+	return al.index(action) >= 0
+}
+
+func (al ActionList) Count() int {
+	return len(al)
+}
+
+func (al ActionList) countOf(action Action) int {
+	n := 0
+	for _, a := range al {
+		if a == action {
+			n++
+		}
+	}
+	return n
+}
+
+func (al ActionList) CountByType(t ActionType) int {
+	n := 0
+	for _, a := range al {
+		if a.Type == t {
+			n++
 		}
 	}
-	return al, false
+	return n
 }
 
 func (al ActionList) Clone() ActionList {
 	return append(al[:0:0], al...)
 }
 
+func lessAction(a, b Action) bool {
+	if a.Type != b.Type {
+		return a.Type < b.Type
+	}
+	return a.Level < b.Level
+}
+
+// Sorted returns a copy of al ordered by Type and then Level.
+func (al ActionList) Sorted() ActionList {
+	r := al.Clone()
+	sort.Slice(r, func(i, j int) bool { return lessAction(r[i], r[j]) })
+	return r
+}
+
+// Equal returns true if al and other have the same actions regardless of the
+// order.
+func (al ActionList) Equal(other ActionList) bool {
+	if len(al) != len(other) {
+		return false
+	}
+	a, b := al.Sorted(), other.Sorted()
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// InfiniteThinkingTime as InitialThinkingTime gives players unlimited clocks.
+// Since an exhausted clock is also 0, use PlayerState.Unlimited to tell them
+// apart during a game.
 const InfiniteThinkingTime time.Duration = 0
 
+// FormatThinkingTime formats d as "m:ss.mmm", or "∞" for InfiniteThinkingTime.
+// Use PlayerState.FormatThinkingTime for the clock of a player, which may be
+// exhausted.
+func FormatThinkingTime(d time.Duration) string {
+	if d == InfiniteThinkingTime {
+		return "∞"
+	}
+	return formatClock(d)
+}
+
+func formatClock(d time.Duration) string {
+	sign := ""
+	if d < 0 {
+		sign, d = "-", -d
+	}
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%s%d:%02d.%03d", sign, ms/60000, ms/1000%60, ms%1000)
+}
+
 type PlayerID uint32
 
 type Player struct {
@@ -61,6 +249,18 @@ func (ps PlayerSet) Get(id PlayerID) (*Player, bool) {
 	return nil, false
 }
 
+// HasDuplicateIDs returns true if two or more players share an ID.
+func (ps PlayerSet) HasDuplicateIDs() bool {
+	ids := make(map[PlayerID]bool, len(ps))
+	for _, p := range ps {
+		if ids[p.ID] {
+			return true
+		}
+		ids[p.ID] = true
+	}
+	return false
+}
+
 type GameSettings struct {
 	Version               string        `json:"version"`
 	Players               PlayerSet     `json:"players"`
@@ -69,25 +269,706 @@ type GameSettings struct {
 	ThinkingTimeIncrement time.Duration `json:"thinkingTimeIncrement"`
 	Actions               ActionList    `json:"actions"`
 	JustGuardPoint        int32         `json:"justGuardPoint"`
+	// ScoreFunc overrides DefaultScore if not nil.
+	ScoreFunc        ScoreFunc        `json:"-"`
+	TimeControlMode  TimeControlMode  `json:"timeControlMode,omitempty"`
+	VictoryCondition VictoryCondition `json:"victoryCondition,omitempty"`
+	// VictoryPoints is the threshold of FirstToPoints.
+	VictoryPoints int32 `json:"victoryPoints,omitempty"`
+	// MaxPoints and MinPoints clamp the points of players if not nil.
+	MaxPoints *int32 `json:"maxPoints,omitempty"`
+	MinPoints *int32 `json:"minPoints,omitempty"`
+	// EndOnMaxPoints ends the game when a player reaches MaxPoints.
+	EndOnMaxPoints bool `json:"endOnMaxPoints,omitempty"`
+	// ByoYomiPeriods is the number of byo-yomi periods of ByoYomiPeriodLength
+	// given after the main thinking time is exhausted.
+	ByoYomiPeriods      int           `json:"byoYomiPeriods,omitempty"`
+	ByoYomiPeriodLength time.Duration `json:"byoYomiPeriodLength,omitempty"`
+	// CooldownRounds, if positive, makes each action set a round of its own
+	// and a used action unavailable for the next CooldownRounds rounds instead
+	// of until the pool is refilled.
+	CooldownRounds uint32 `json:"cooldownRounds,omitempty"`
+	// ComboBonus, if not nil, returns the bonus points added to a hit which
+	// extended the attacker's streak to streak. A just guard is not a hit and
+	// resets the streak.
+	ComboBonus func(streak int) int32 `json:"-"`
+	// InitialPoints are the handicaps given to players at the beginning.
+	// Unlisted players start at 0.
+	InitialPoints map[PlayerID]int32 `json:"initialPoints,omitempty"`
+	// PlayerActions are the action pools of individual players.
+	// Unlisted players use Actions.
+	PlayerActions map[PlayerID]ActionList `json:"playerActions,omitempty"`
+	// AllowPass allows players to take Pass actions.
+	AllowPass bool `json:"allowPass,omitempty"`
+	// Teams groups players by team numbers. Unlisted players have no team.
+	Teams map[PlayerID]int `json:"teams,omitempty"`
+	// FriendlyFire allows attacks on teammates.
+	FriendlyFire bool `json:"friendlyFire,omitempty"`
+	// JustGuardCounter is subtracted from the points of the attacker on a just
+	// guard in addition to JustGuardPoint for the defender. It must not be
+	// negative.
+	JustGuardCounter int32 `json:"justGuardCounter,omitempty"`
+	// BlockedAttackPenalty is subtracted from the points of the attacker per
+	// level of the attack when it is blocked by a higher defence, so a higher
+	// attack is riskier. It must not be negative.
+	BlockedAttackPenalty int32 `json:"blockedAttackPenalty,omitempty"`
+	// RoundAdvancePolicy is ignored under CooldownRounds.
+	RoundAdvancePolicy RoundAdvancePolicy `json:"roundAdvancePolicy,omitempty"`
+	// ResetPointsEachRound gives a round win to the single point leader of
+	// each round and resets the points to InitialPoints on round advance.
+	// The winner is decided by GameState.RoundWins instead of points.
+	ResetPointsEachRound bool `json:"resetPointsEachRound,omitempty"`
+	// MaxThinkingTime, if positive, caps the thinking time after an increment
+	// so that clocks do not grow forever.
+	MaxThinkingTime time.Duration `json:"maxThinkingTime,omitempty"`
+	// AllowSelfTarget allows players to attack themselves. Such an attack is
+	// scored against the attacker's own attack, i.e. undefended by default,
+	// and both deltas of the score are added to the attacker.
+	AllowSelfTarget bool `json:"allowSelfTarget,omitempty"`
+	// AttackClashMode decides how DefaultScore resolves an attack on a player
+	// who also attacked.
+	AttackClashMode AttackClashMode `json:"attackClashMode,omitempty"`
+	// MinThinkingTimeConsumption, if positive, is the least thinking time
+	// which an action must consume, except for Unlimited clocks.
+	MinThinkingTimeConsumption time.Duration `json:"minThinkingTimeConsumption,omitempty"`
+	// TieBreakers rank players tied for the lead in order, both for the
+	// winner and Scores.
+	TieBreakers []TieBreaker `json:"tieBreakers,omitempty"`
+	// TrackUsedActions keeps the whole pool in PlayerState.Actions and records
+	// the used actions in PlayerState.UsedActions instead, so that UIs can
+	// show them. It cannot be combined with CooldownRounds.
+	TrackUsedActions bool `json:"trackUsedActions,omitempty"`
+	// MaxRounds, if positive, ends the game once that many action sets are
+	// applied, so that the game terminates even if rounds stall, e.g. by
+	// passing forever.
+	MaxRounds uint32 `json:"maxRounds,omitempty"`
+	// EliminateAtMinPoints eliminates players whose points are at MinPoints
+	// after an action set. InitialPoints must exceed MinPoints.
+	EliminateAtMinPoints bool `json:"eliminateAtMinPoints,omitempty"`
+	// CounterMargin is the least level difference by which a Counter must
+	// exceed an attack to hurt the attacker. Values below 1 mean 1.
+	CounterMargin int32 `json:"counterMargin,omitempty"`
+	// ActionsPerRound limits the actions each player submits in a round if
+	// positive, so that a round can be over before the pool is used up.
+	// ActionsPerRound is ignored under CooldownRounds.
+	ActionsPerRound int `json:"actionsPerRound,omitempty"`
+}
+
+// Clone returns a deep copy of s. Players are copied too, while ScoreFunc and
+// ComboBonus are shared.
+func (s *GameSettings) Clone() *GameSettings {
+	c := *s
+	if s.Players != nil {
+		c.Players = make(PlayerSet, 0, len(s.Players))
+		for _, p := range s.Players {
+			copied := *p
+			c.Players = append(c.Players, &copied)
+		}
+	}
+	c.Actions = s.Actions.Clone()
+	if s.MaxPoints != nil {
+		c.MaxPoints = PointsLimit(*s.MaxPoints)
+	}
+	if s.MinPoints != nil {
+		c.MinPoints = PointsLimit(*s.MinPoints)
+	}
+	if s.InitialPoints != nil {
+		c.InitialPoints = make(map[PlayerID]int32, len(s.InitialPoints))
+		for id, p := range s.InitialPoints {
+			c.InitialPoints[id] = p
+		}
+	}
+	if s.Teams != nil {
+		c.Teams = make(map[PlayerID]int, len(s.Teams))
+		for id, team := range s.Teams {
+			c.Teams[id] = team
+		}
+	}
+	if s.TieBreakers != nil {
+		c.TieBreakers = append([]TieBreaker(nil), s.TieBreakers...)
+	}
+	if s.PlayerActions != nil {
+		c.PlayerActions = make(map[PlayerID]ActionList, len(s.PlayerActions))
+		for id, as := range s.PlayerActions {
+			c.PlayerActions[id] = as.Clone()
+		}
+	}
+	return &c
+}
+
+// actionsOf returns the action pool of the player.
+func (s *GameSettings) actionsOf(id PlayerID) ActionList {
+	if as, found := s.PlayerActions[id]; found {
+		return as
+	}
+	return s.Actions
+}
+
+// actionsPerRoundOf returns the number of actions the player submits in a
+// round.
+func (s *GameSettings) actionsPerRoundOf(id PlayerID) int {
+	return s.actionsPerRound(len(s.actionsOf(id)))
+}
+
+// actionsPerRound returns the number of actions submitted in a round from a
+// pool of n actions.
+func (s *GameSettings) actionsPerRound(n int) int {
+	if s.ActionsPerRound > 0 && s.CooldownRounds == 0 && s.ActionsPerRound < n {
+		return s.ActionsPerRound
+	}
+	return n
+}
+
+// actionsLeft returns the number of actions the player can still submit in
+// the current round.
+func (s *GameSettings) actionsLeft(ps *PlayerState) int {
+	n := len(ps.AvailableActions())
+	if s.ActionsPerRound > 0 && s.CooldownRounds == 0 {
+		used := len(s.actionsOf(ps.PlayerID)) - n
+		if left := s.ActionsPerRound - used; left < n {
+			n = left
+		}
+	}
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+// TimeControlMode decides how ThinkingTimeIncrement is given back.
+type TimeControlMode int8
+
+const (
+	// Fischer adds ThinkingTimeIncrement after every action.
+	Fischer TimeControlMode = iota
+	// Bronstein gives back the consumed time up to ThinkingTimeIncrement, so
+	// the clock never grows.
+	Bronstein
+)
+
+// consumeThinkingTime charges consumption to the clock of ps.
+// Unlimited clocks are never charged.
+// Once the main thinking time is exhausted, an action must finish within a
+// byo-yomi period. Every period which it exceeds is used up, and the player is
+// over thinking time if no period would remain. No increment is given in
+// byo-yomi.
+func (s *GameSettings) consumeThinkingTime(ps *PlayerState, consumption time.Duration) error {
+	if ps.Unlimited {
+		return nil
+	}
+	if ps.CanAfford(consumption) {
+		ps.ThinkingTime -= consumption
+		ps.ThinkingTime += s.increment(consumption)
+		if s.MaxThinkingTime > 0 && ps.ThinkingTime > s.MaxThinkingTime {
+			ps.ThinkingTime = s.MaxThinkingTime
+		}
+		return nil
+	}
+	if s.ByoYomiPeriodLength <= 0 {
+		return &TimeoutError{PlayerID: ps.PlayerID}
+	}
+	used := int((consumption - ps.ThinkingTime - 1) / s.ByoYomiPeriodLength)
+	if used >= ps.ByoYomiPeriods {
+		return &TimeoutError{PlayerID: ps.PlayerID}
+	}
+	ps.ThinkingTime = 0
+	ps.ByoYomiPeriods -= used
+	return nil
+}
+
+// checkMinConsumption returns ErrUnderThinkingTime if consumption is below
+// MinThinkingTimeConsumption.
+func (s *GameSettings) checkMinConsumption(ps *PlayerState, consumption time.Duration) error {
+	if !ps.Unlimited && consumption < s.MinThinkingTimeConsumption {
+		return fmt.Errorf("player (id: %d): %w", ps.PlayerID, ErrUnderThinkingTime)
+	}
+	return nil
+}
+
+// minConsumption returns MinThinkingTimeConsumption and whether ps can afford
+// it.
+func (s *GameSettings) minConsumption(ps *PlayerState) (time.Duration, bool) {
+	floor := s.MinThinkingTimeConsumption
+	return floor, s.consumeThinkingTime(ps.Clone(), floor) == nil
+}
+
+// increment returns the thinking time given back after consuming consumption.
+func (s *GameSettings) increment(consumption time.Duration) time.Duration {
+	if s.TimeControlMode == Bronstein && consumption < s.ThinkingTimeIncrement {
+		return consumption
+	}
+	return s.ThinkingTimeIncrement
+}
+
+// AttackClashMode decides how an attack on an attacking player is scored.
+// The attack of the target is compared whoever it targets, since each attack
+// is resolved independently.
+type AttackClashMode int8
+
+const (
+	// BothAttacksScore lets each attack score its level as if undefended.
+	BothAttacksScore AttackClashMode = iota
+	// HigherAttackWins lets only the higher attack score the level
+	// difference. Attacks of the same level score nothing.
+	HigherAttackWins
+)
+
+// TieBreaker ranks players with the same points or round wins.
+type TieBreaker int8
+
+const (
+	// MoreThinkingTime ranks more remaining thinking time higher.
+	// Unlimited is more than any.
+	MoreThinkingTime TieBreaker = iota
+	// FewerActionsUsed ranks fewer actions taken from the pool in ActionLogs
+	// higher.
+	FewerActionsUsed
+	// LowerPlayerID ranks a lower ID higher, so it resolves any tie.
+	LowerPlayerID
+)
+
+// compareTieBreakers returns a positive value if a ranks above b by
+// TieBreakers, a negative value if below and 0 if still tied.
+func (g *Game) compareTieBreakers(a, b *PlayerState) int {
+	for _, tb := range g.Settings.TieBreakers {
+		c := 0
+		switch tb {
+		case MoreThinkingTime:
+			if a.Unlimited != b.Unlimited {
+				if a.Unlimited {
+					c = 1
+				} else {
+					c = -1
+				}
+			} else if a.ThinkingTime != b.ThinkingTime {
+				if a.ThinkingTime > b.ThinkingTime {
+					c = 1
+				} else {
+					c = -1
+				}
+			}
+		case FewerActionsUsed:
+			c = g.actionsUsed(b.PlayerID) - g.actionsUsed(a.PlayerID)
+		case LowerPlayerID:
+			if a.PlayerID < b.PlayerID {
+				c = 1
+			} else if a.PlayerID > b.PlayerID {
+				c = -1
+			}
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
 }
 
+// actionsUsed returns the number of the actions in ActionLogs which playerID
+// took from the pool.
+func (g *Game) actionsUsed(playerID PlayerID) int {
+	n := 0
+	for _, pas := range g.ActionLogs {
+		if pas.outOfBand() {
+			continue
+		}
+		if pa, found := pas.Get(playerID); found && pa.Action.Type != Pass {
+			n++
+		}
+	}
+	return n
+}
+
+// leaders returns the leading players of the state after TieBreakers.
+func (g *Game) leaders() PlayerStateSet {
+	leaders := g.State.leaders(g.Settings)
+	if len(leaders) < 2 || len(g.Settings.TieBreakers) == 0 {
+		return leaders
+	}
+	return (&GameState{PlayerStates: leaders}).leadersBy(g.compareTieBreakers)
+}
+
+// RoundAdvancePolicy decides when a round is over.
+type RoundAdvancePolicy int8
+
+const (
+	// AnyPlayerEmpty ends a round when any player used up the actions.
+	AnyPlayerEmpty RoundAdvancePolicy = iota
+	// AllPlayersEmpty ends a round when every player used up the actions.
+	// Players who did so earlier wait without submitting actions.
+	AllPlayersEmpty
+)
+
+// actingPlayers returns the players who have to submit actions in state.
+// Eliminated players never act.
+func (g *Game) actingPlayers(state *GameState) PlayerSet {
+	r := make(PlayerSet, 0, len(g.Settings.Players))
+	for _, p := range g.Settings.Players {
+		var ps *PlayerState
+		if state != nil {
+			ps, _ = state.PlayerStates.Get(p.ID)
+		}
+		if ps != nil && ps.Status == Eliminated {
+			continue
+		}
+		if g.Settings.RoundAdvancePolicy == AllPlayersEmpty && (ps == nil || g.Settings.actionsLeft(ps) == 0) {
+			continue
+		}
+		r = append(r, p)
+	}
+	return r
+}
+
+type VictoryCondition int8
+
+const (
+	// HighestTotal is won by the highest points after all games.
+	HighestTotal VictoryCondition = iota
+	// FirstToPoints ends the game as soon as a player reaches VictoryPoints.
+	// The highest points win as in HighestTotal.
+	FirstToPoints
+	// LowestTotal is won by the lowest points after all games.
+	LowestTotal
+)
+
+// comparePoints returns a positive value if a ranks above b, a negative value
+// if a ranks below b and 0 if they are equal.
+func (s *GameSettings) comparePoints(a, b int32) int {
+	if a == b {
+		return 0
+	}
+	if (a > b) == (s.VictoryCondition != LowestTotal) {
+		return 1
+	}
+	return -1
+}
+
+// reachedVictoryPoints returns true if a player of state reached
+// VictoryPoints under FirstToPoints.
+func (s *GameSettings) reachedVictoryPoints(state *GameState) bool {
+	if s.VictoryCondition != FirstToPoints {
+		return false
+	}
+	for _, ps := range state.PlayerStates {
+		if ps.Points >= s.VictoryPoints {
+			return true
+		}
+	}
+	return false
+}
+
+// PointsLimit returns a pointer to p for MaxPoints and MinPoints.
+func PointsLimit(p int32) *int32 {
+	return &p
+}
+
+func (s *GameSettings) clampPoints(p int32) int32 {
+	if s.MaxPoints != nil && p > *s.MaxPoints {
+		return *s.MaxPoints
+	}
+	if s.MinPoints != nil && p < *s.MinPoints {
+		return *s.MinPoints
+	}
+	return p
+}
+
+// ScoreFunc returns the points which an attacker and its target gain when the
+// attacker attacks with attacker and the target takes the defender action.
+// Note that defender can also be an attack.
+type ScoreFunc func(attacker, defender Action, settings *GameSettings) (attackerDelta, defenderDelta int32)
+
+// DefaultScore is the standard rule.
+// An attack against a defence scores the level difference if it exceeds the
+// defence. If the levels are exactly equal, it is a just guard and the
+// defender scores JustGuardPoint instead while the attacker loses
+// JustGuardCounter. An attack below the defence is blocked and costs the
+// attacker BlockedAttackPenalty per level of the attack. A Counter scores as a
+// defence, except that when it exceeds the attack by CounterMargin or more,
+// the attacker loses the level difference instead of the penalty. An attack
+// against another attack is scored by AttackClashMode. An attack against any other
+// action scores its level.
+func DefaultScore(attacker, defender Action, settings *GameSettings) (attackerDelta, defenderDelta int32) {
+	switch defender.Type {
+	case Defence, Counter:
+		points := attacker.Level.Sub(defender.Level)
+		if points > 0 {
+			return int32(points), 0
+		} else if points == 0 {
+			return -settings.JustGuardCounter, settings.JustGuardPoint
+		}
+		margin := settings.CounterMargin
+		if margin < 1 {
+			margin = 1
+		}
+		if defender.Type == Counter && -int32(points) >= margin {
+			return int32(points), 0
+		}
+		return -settings.BlockedAttackPenalty * int32(attacker.Level), 0
+	case Attack:
+		if settings.AttackClashMode == HigherAttackWins {
+			if points := attacker.Level.Sub(defender.Level); points > 0 {
+				return int32(points), 0
+			}
+			return 0, 0
+		}
+		return int32(attacker.Level), 0
+	default:
+		return int32(attacker.Level), 0
+	}
+}
+
+func (s *GameSettings) score(attacker, defender Action) (attackerDelta, defenderDelta int32) {
+	if s.ScoreFunc != nil {
+		return s.ScoreFunc(attacker, defender, s)
+	}
+	return DefaultScore(attacker, defender, s)
+}
+
+// Validate reports all the problems of s at once.
+func (s *GameSettings) Validate() error {
+	var errs []error
+	if len(s.Players) < 2 {
+		errs = append(errs, errors.New("at least two players are required"))
+	}
+	ids := make(map[PlayerID]int, len(s.Players))
+	for _, p := range s.Players {
+		ids[p.ID]++
+		if ids[p.ID] == 2 {
+			errs = append(errs, fmt.Errorf("duplicate player id: %d", p.ID))
+		}
+	}
+	if s.TotalGames < 1 {
+		errs = append(errs, errors.New("total games must be at least 1"))
+	}
+	if s.InitialThinkingTime < 0 {
+		errs = append(errs, errors.New("initial thinking time must not be negative"))
+	}
+	if s.ThinkingTimeIncrement < 0 {
+		errs = append(errs, errors.New("thinking time increment must not be negative"))
+	}
+	if s.EliminateAtMinPoints {
+		if s.MinPoints == nil {
+			errs = append(errs, errors.New("eliminate at min points requires min points"))
+		} else {
+			for _, p := range s.Players {
+				if s.InitialPoints[p.ID] <= *s.MinPoints {
+					errs = append(errs, fmt.Errorf("initial points of player (id: %d) must exceed min points", p.ID))
+				}
+			}
+		}
+	}
+	if s.TrackUsedActions && s.CooldownRounds > 0 {
+		errs = append(errs, errors.New("track used actions cannot be combined with cooldown rounds"))
+	}
+	for _, tb := range s.TieBreakers {
+		if tb < MoreThinkingTime || tb > LowerPlayerID {
+			errs = append(errs, fmt.Errorf("invalid tie breaker: %d", tb))
+		}
+	}
+	if s.MinThinkingTimeConsumption < 0 {
+		errs = append(errs, errors.New("min thinking time consumption must not be negative"))
+	}
+	if s.MaxThinkingTime < 0 {
+		errs = append(errs, errors.New("max thinking time must not be negative"))
+	} else if s.MaxThinkingTime > 0 && s.MaxThinkingTime < s.InitialThinkingTime {
+		errs = append(errs, errors.New("max thinking time must not be less than initial thinking time"))
+	}
+	if s.JustGuardCounter < 0 {
+		errs = append(errs, errors.New("just guard counter must not be negative"))
+	}
+	if s.BlockedAttackPenalty < 0 {
+		errs = append(errs, errors.New("blocked attack penalty must not be negative"))
+	}
+	if s.CounterMargin < 0 {
+		errs = append(errs, errors.New("counter margin must not be negative"))
+	}
+	if s.ActionsPerRound < 0 {
+		errs = append(errs, errors.New("actions per round must not be negative"))
+	}
+	if s.ByoYomiPeriods < 0 || s.ByoYomiPeriodLength < 0 {
+		errs = append(errs, errors.New("byo-yomi must not be negative"))
+	}
+	validateActions := func(as ActionList) {
+		if len(as) == 0 {
+			errs = append(errs, errors.New("no removable action"))
+		}
+		for _, a := range as {
+			if a.Type != Attack && !a.Type.defends() && a.Type != Swap {
+				errs = append(errs, fmt.Errorf("invalid action type: %d", a.Type))
+			}
+			if !a.Level.IsValid(MinActionLevel, MaxActionLevel) {
+				errs = append(errs, fmt.Errorf("action level out of range: %d", a.Level))
+			}
+		}
+		if s.CooldownRounds > 0 && int(s.CooldownRounds) >= len(as) {
+			errs = append(errs, errors.New("cooldown rounds must be less than the number of actions"))
+		}
+	}
+	validateActions(s.Actions)
+	for id, as := range s.PlayerActions {
+		if _, found := s.Players.Get(id); !found {
+			errs = append(errs, fmt.Errorf("actions of unknown player (id: %d)", id))
+		}
+		validateActions(as)
+	}
+	for id := range s.Teams {
+		if _, found := s.Players.Get(id); !found {
+			errs = append(errs, fmt.Errorf("team of unknown player (id: %d)", id))
+		}
+	}
+	for id, p := range s.InitialPoints {
+		if _, found := s.Players.Get(id); !found {
+			errs = append(errs, fmt.Errorf("initial points of unknown player (id: %d)", id))
+		}
+		if s.MaxPoints != nil && p > *s.MaxPoints {
+			errs = append(errs, fmt.Errorf("initial points of player (id: %d) exceed max points", id))
+		}
+		if s.VictoryCondition == FirstToPoints && s.VictoryPoints > 0 && p >= s.VictoryPoints {
+			errs = append(errs, fmt.Errorf("initial points of player (id: %d) reach victory points", id))
+		}
+	}
+	if s.MaxPoints != nil && s.MinPoints != nil && *s.MinPoints > *s.MaxPoints {
+		errs = append(errs, errors.New("min points must not exceed max points"))
+	}
+	if s.VictoryCondition == FirstToPoints && s.VictoryPoints <= 0 {
+		errs = append(errs, errors.New("victory points must be positive"))
+	}
+	if s.EndOnMaxPoints && s.MaxPoints == nil {
+		errs = append(errs, errors.New("max points is required to end on max points"))
+	}
+	return errors.Join(errs...)
+}
+
+type PlayerStatus int8
+
+const (
+	Playing PlayerStatus = iota
+	// TimedOut means the player ran out of the clock and lost.
+	TimedOut
+	// Forfeited means the player resigned.
+	Forfeited
+	// Eliminated means the player left the game which the others continue.
+	// It neither acts nor can be targeted. See Game.Eliminate.
+	Eliminated
+)
+
 type PlayerState struct {
-	PlayerID PlayerID `json:"playerId"`
+	PlayerID PlayerID     `json:"playerId"`
+	Status   PlayerStatus `json:"status,omitempty"`
 	// Current points.
 	Points int32 `json:"points"`
 	// Remaining thinking time.
 	ThinkingTime time.Duration `json:"thinkingTime"`
-	// Available actions.
+	// Unlimited is true if the game started with InfiniteThinkingTime.
+	Unlimited bool `json:"unlimited,omitempty"`
+	// Remaining byo-yomi periods.
+	ByoYomiPeriods int `json:"byoYomiPeriods,omitempty"`
+	// Streak is the number of consecutive hits by the player.
+	Streak int `json:"streak,omitempty"`
+	// Available actions, or the whole pool under TrackUsedActions.
+	// Use AvailableActions to see the available ones in either mode.
 	Actions ActionList `json:"actions"`
+	// Actions used in the current round, used only if TrackUsedActions.
+	UsedActions ActionList `json:"usedActions,omitempty"`
+	// Actions on cooldown, used only if CooldownRounds is positive.
+	Cooldowns []Cooldown `json:"cooldowns,omitempty"`
+	// PointsHistory is the points at the beginning of the game followed by
+	// those at the end of each finished round, so its length is the number of
+	// the started rounds. It is not updated once the game is over.
+	PointsHistory []int32 `json:"pointsHistory,omitempty"`
+}
+
+// AvailableActions returns Actions minus UsedActions. It returns Actions
+// itself if no action is used, so it must not be modified.
+func (s *PlayerState) AvailableActions() ActionList {
+	if len(s.UsedActions) == 0 {
+		return s.Actions
+	}
+	r := s.Actions
+	for _, a := range s.UsedActions {
+		r, _ = r.Remove(a)
+	}
+	return r
+}
+
+// Cooldown is an action which will return to the pool after Rounds rounds.
+type Cooldown struct {
+	Action Action `json:"action"`
+	Rounds uint32 `json:"rounds"`
 }
 
 func (s *PlayerState) Clone() *PlayerState {
+	var cooldowns []Cooldown
+	if s.Cooldowns != nil {
+		cooldowns = append(make([]Cooldown, 0, len(s.Cooldowns)), s.Cooldowns...)
+	}
+	var usedActions ActionList
+	if s.UsedActions != nil {
+		usedActions = s.UsedActions.Clone()
+	}
+	var history []int32
+	if s.PointsHistory != nil {
+		history = append(make([]int32, 0, len(s.PointsHistory)), s.PointsHistory...)
+	}
 	return &PlayerState{
-		PlayerID:     s.PlayerID,
-		Points:       s.Points,
-		ThinkingTime: s.ThinkingTime,
-		Actions:      s.Actions.Clone(),
+		PlayerID:       s.PlayerID,
+		Status:         s.Status,
+		Points:         s.Points,
+		ThinkingTime:   s.ThinkingTime,
+		Unlimited:      s.Unlimited,
+		ByoYomiPeriods: s.ByoYomiPeriods,
+		Streak:         s.Streak,
+		Actions:        s.Actions.Clone(),
+		UsedActions:    usedActions,
+		Cooldowns:      cooldowns,
+		PointsHistory:  history,
+	}
+}
+
+// advanceCooldowns returns the actions whose cooldown expired to the pool.
+func (s *PlayerState) advanceCooldowns() {
+	cooldowns := s.Cooldowns[:0]
+	for _, c := range s.Cooldowns {
+		if c.Rounds == 0 {
+			s.Actions = append(s.Actions, c.Action)
+			continue
+		}
+		c.Rounds--
+		cooldowns = append(cooldowns, c)
+	}
+	s.Cooldowns = cooldowns
+}
+
+// CanAfford returns true if s is Unlimited or the remaining ThinkingTime
+// covers consumption. Byo-yomi periods are not taken into account.
+func (s *PlayerState) CanAfford(consumption time.Duration) bool {
+	return s.Unlimited || consumption <= s.ThinkingTime
+}
+
+// TimePerRemainingAction returns ThinkingTime divided by the number of the
+// available actions, or the whole ThinkingTime if no action is available.
+// It returns InfiniteThinkingTime if s is Unlimited. ActionsPerRound is not
+// taken into account, for which use Game.TimePerRemainingAction.
+func (s *PlayerState) TimePerRemainingAction() time.Duration {
+	return s.timePerAction(len(s.AvailableActions()))
+}
+
+func (s *PlayerState) timePerAction(n int) time.Duration {
+	if s.Unlimited {
+		return InfiniteThinkingTime
+	}
+	if n <= 0 {
+		return s.ThinkingTime
+	}
+	return s.ThinkingTime / time.Duration(n)
+}
+
+// FormatThinkingTime formats the clock of s. Unlike the function of the same
+// name, an exhausted clock is "0:00.000" unless s is Unlimited.
+func (s *PlayerState) FormatThinkingTime() string {
+	if s.Unlimited {
+		return "∞"
 	}
+	return formatClock(s.ThinkingTime)
 }
 
 type PlayerStateSet []*PlayerState
@@ -116,6 +997,24 @@ type PlayerAction struct {
 	ThinkingTimeConsumption time.Duration `json:"thinkingTimeConsumption"`
 }
 
+// String returns e.g. "player 1 -> player 2: Attack L3 (consumed 500ms)".
+func (pa PlayerAction) String() string {
+	if pa.Action.Type == Forfeit {
+		return fmt.Sprintf("player %d resigned", pa.PlayerID)
+	}
+	if pa.Action.Type == Eliminate {
+		return fmt.Sprintf("player %d was eliminated", pa.PlayerID)
+	}
+	if pa.Action.Type == Timeout {
+		return fmt.Sprintf("player %d timed out", pa.PlayerID)
+	}
+	if pa.Action.Type == Pass {
+		return fmt.Sprintf("player %d passed (consumed %v)", pa.PlayerID, pa.ThinkingTimeConsumption)
+	}
+	return fmt.Sprintf("player %d -> player %d: %v (consumed %v)",
+		pa.PlayerID, pa.TargetPlayerID, pa.Action, pa.ThinkingTimeConsumption)
+}
+
 type PlayerActionSet []*PlayerAction
 
 func (pas PlayerActionSet) Get(id PlayerID) (*PlayerAction, bool) {
@@ -127,21 +1026,83 @@ func (pas PlayerActionSet) Get(id PlayerID) (*PlayerAction, bool) {
 	return nil, false
 }
 
-const GameOver uint32 = 0
-
-type GameState struct {
-	GameNum      uint32         `json:"gameNum"`
+func (pas PlayerActionSet) Clone() PlayerActionSet {
+	if pas == nil {
+		return nil
+	}
+	r := make(PlayerActionSet, 0, len(pas))
+	for _, pa := range pas {
+		copied := *pa
+		r = append(r, &copied)
+	}
+	return r
+}
+
+// Validate checks that pas has exactly one action for each of players.
+func (pas PlayerActionSet) Validate(players PlayerSet) error {
+	seen := make(map[PlayerID]bool, len(pas))
+	for _, pa := range pas {
+		if seen[pa.PlayerID] {
+			return fmt.Errorf("player (id: %d) action is duplicated", pa.PlayerID)
+		}
+		seen[pa.PlayerID] = true
+		if _, found := players.Get(pa.PlayerID); !found {
+			return fmt.Errorf("player (id: %d) is not in the game", pa.PlayerID)
+		}
+	}
+	for _, p := range players {
+		if !seen[p.ID] {
+			return fmt.Errorf("player (id: %d) action is missing", p.ID)
+		}
+	}
+	return nil
+}
+
+// IsForfeit returns true if pas is an entry recorded by Game.Forfeit.
+func (pas PlayerActionSet) IsForfeit() bool {
+	return len(pas) == 1 && pas[0].Action.Type == Forfeit
+}
+
+// IsElimination returns true if pas is an entry recorded by Game.Eliminate.
+func (pas PlayerActionSet) IsElimination() bool {
+	return len(pas) == 1 && pas[0].Action.Type == Eliminate
+}
+
+// IsTimeout returns true if pas is an entry recorded by Game.Timeout.
+func (pas PlayerActionSet) IsTimeout() bool {
+	return len(pas) == 1 && pas[0].Action.Type == Timeout
+}
+
+// outOfBand returns true if pas is an entry recorded by a method of Game
+// rather than actions played by the players.
+func (pas PlayerActionSet) outOfBand() bool {
+	return pas.IsForfeit() || pas.IsElimination() || pas.IsTimeout()
+}
+
+const GameOver uint32 = 0
+
+type GameState struct {
+	GameNum      uint32         `json:"gameNum"`
 	PlayerStates PlayerStateSet `json:"playerStates"`
+	// RoundWins is the number of rounds each player won under
+	// ResetPointsEachRound.
+	RoundWins map[PlayerID]int `json:"roundWins,omitempty"`
+	// Turns is the number of the applied action sets except for the entries
+	// recorded by Forfeit, Eliminate and Timeout.
+	Turns uint32 `json:"turns,omitempty"`
 }
 
 func NewGameState(settings *GameSettings) *GameState {
 	pss := make(PlayerStateSet, 0, len(settings.Players))
 	for _, p := range settings.Players {
 		pss = append(pss, &PlayerState{
-			PlayerID:     p.ID,
-			Points:       0,
-			ThinkingTime: settings.InitialThinkingTime,
-			Actions:      settings.Actions.Clone(),
+			PlayerID:       p.ID,
+			Points:         settings.InitialPoints[p.ID],
+			ThinkingTime:   settings.InitialThinkingTime,
+			Unlimited:      settings.InitialThinkingTime == InfiniteThinkingTime,
+			ByoYomiPeriods: settings.ByoYomiPeriods,
+			Actions:        settings.actionsOf(p.ID).Clone(),
+			PointsHistory:  []int32{settings.InitialPoints[p.ID]},
 		})
 	}
 	return &GameState{
@@ -150,93 +1111,956 @@ func NewGameState(settings *GameSettings) *GameState {
 	}
 }
 
+// IsGameOver returns true if GameNum reached GameOver.
+func (s *GameState) IsGameOver() bool {
+	return s.GameNum == GameOver
+}
+
+// ActivePlayers returns the players who are still playing, i.e. neither
+// eliminated, timed out nor forfeited.
+func (s *GameState) ActivePlayers() []PlayerID {
+	var r []PlayerID
+	for _, ps := range s.PlayerStates {
+		if ps.Status == Playing {
+			r = append(r, ps.PlayerID)
+		}
+	}
+	return r
+}
+
+// leaders returns the playing player states which have the best points under
+// the victory condition of settings.
+// Under ResetPointsEachRound, RoundWins are compared instead of points.
+func (s *GameState) leaders(settings *GameSettings) PlayerStateSet {
+	if settings.ResetPointsEachRound {
+		return s.leadersBy(func(a, b *PlayerState) int {
+			return s.RoundWins[a.PlayerID] - s.RoundWins[b.PlayerID]
+		})
+	}
+	return s.pointLeaders(settings)
+}
+
+func (s *GameState) pointLeaders(settings *GameSettings) PlayerStateSet {
+	return s.leadersBy(func(a, b *PlayerState) int {
+		return settings.comparePoints(a.Points, b.Points)
+	})
+}
+
+func (s *GameState) leadersBy(compare func(a, b *PlayerState) int) PlayerStateSet {
+	if s == nil {
+		return nil
+	}
+	var r PlayerStateSet
+	for _, ps := range s.PlayerStates {
+		if ps.Status != Playing {
+			continue
+		}
+		if len(r) == 0 {
+			r = PlayerStateSet{ps}
+			continue
+		}
+		switch c := compare(ps, r[0]); {
+		case c > 0:
+			r = PlayerStateSet{ps}
+		case c == 0:
+			r = append(r, ps)
+		}
+	}
+	return r
+}
+
+// tallyRound gives a round win to the single point leader of the round.
+func (s *GameState) tallyRound(settings *GameSettings) {
+	leaders := s.pointLeaders(settings)
+	if len(leaders) != 1 {
+		return
+	}
+	if s.RoundWins == nil {
+		s.RoundWins = make(map[PlayerID]int)
+	}
+	s.RoundWins[leaders[0].PlayerID]++
+}
+
 func (s *GameState) Clone() *GameState {
+	var roundWins map[PlayerID]int
+	if s.RoundWins != nil {
+		roundWins = make(map[PlayerID]int, len(s.RoundWins))
+		for id, n := range s.RoundWins {
+			roundWins[id] = n
+		}
+	}
 	return &GameState{
 		GameNum:      s.GameNum,
 		PlayerStates: s.PlayerStates.Clone(),
+		RoundWins:    roundWins,
+		Turns:        s.Turns,
 	}
 }
 
+// Hash returns the FNV-1a hash of s for caching and deduplication.
+// It does not depend on the order of PlayerStates and Actions, so equal
+// states always hash equal.
+func (s *GameState) Hash() uint64 {
+	h := fnv.New64a()
+	write := func(v interface{}) {
+		binary.Write(h, binary.LittleEndian, v)
+	}
+	write(s.GameNum)
+	write(s.Turns)
+	pss := append(PlayerStateSet(nil), s.PlayerStates...)
+	sort.Slice(pss, func(i, j int) bool { return pss[i].PlayerID < pss[j].PlayerID })
+	for _, ps := range pss {
+		write(ps.PlayerID)
+		write(ps.Status)
+		write(ps.Unlimited)
+		write(ps.Points)
+		write(int64(ps.ThinkingTime))
+		write(int64(ps.ByoYomiPeriods))
+		write(int64(ps.Streak))
+		write(int64(s.RoundWins[ps.PlayerID]))
+		write([]Action(ps.Actions.Sorted()))
+		write(int64(len(ps.UsedActions)))
+		write([]Action(ps.UsedActions.Sorted()))
+		cooldowns := sortedCooldowns(ps.Cooldowns)
+		write(int64(len(cooldowns)))
+		for _, c := range cooldowns {
+			write(c.Action)
+			write(c.Rounds)
+		}
+	}
+	return h.Sum64()
+}
+
+// sortedCooldowns returns a sorted copy of cs.
+func sortedCooldowns(cs []Cooldown) []Cooldown {
+	r := append([]Cooldown(nil), cs...)
+	sort.Slice(r, func(i, j int) bool {
+		return lessAction(r[i].Action, r[j].Action) ||
+			r[i].Action == r[j].Action && r[i].Rounds < r[j].Rounds
+	})
+	return r
+}
+
+// DefaultSeed is the seed of games created by NewGame.
+const DefaultSeed int64 = 1
+
 type Game struct {
 	Settings   *GameSettings     `json:"settings"`
 	ActionLogs []PlayerActionSet `json:"actionLogs"`
 	State      *GameState        `json:"state"`
+	// Events describe the outcomes of ActionLogs in order.
+	Events []GameEvent `json:"events"`
+	// PendingActions are the actions submitted by SubmitAction which are not
+	// applied yet.
+	PendingActions PlayerActionSet `json:"pendingActions,omitempty"`
+	// Seed is the seed of Rand.
+	Seed int64 `json:"seed"`
+	// Rand is the source of all randomized logic of the game.
+	Rand *rand.Rand `json:"-"`
+	// Previous is the game which this game is a rematch of, if any.
+	Previous *Game `json:"-"`
+	// Parent is the game which this game was forked from, if any.
+	Parent    *Game `json:"-"`
+	observers []Observer
 }
 
 func NewGame(settings *GameSettings) *Game {
+	return NewGameWithSeed(settings, DefaultSeed)
+}
+
+func NewGameWithSeed(settings *GameSettings, seed int64) *Game {
 	return &Game{
 		Settings:   settings,
 		ActionLogs: make([]PlayerActionSet, 0),
 		State:      NewGameState(settings),
+		Events:     make([]GameEvent, 0),
+		Seed:       seed,
+		Rand:       rand.New(rand.NewSource(seed)),
+	}
+}
+
+// NewGameChecked is like NewGame but validates settings first.
+func NewGameChecked(settings *GameSettings) (*Game, error) {
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+	return NewGame(settings), nil
+}
+
+// Clone returns a deep copy of g.
+// Settings are shared since they must not be changed during a game.
+// Observers are not copied and Rand of the copy is reset to Seed.
+func (g *Game) Clone() *Game {
+	logs := make([]PlayerActionSet, 0, len(g.ActionLogs))
+	for _, pas := range g.ActionLogs {
+		logs = append(logs, pas.Clone())
 	}
+	return &Game{
+		Settings:       g.Settings,
+		ActionLogs:     logs,
+		State:          g.State.Clone(),
+		Events:         append(g.Events[:0:0], g.Events...),
+		PendingActions: g.PendingActions.Clone(),
+		Seed:           g.Seed,
+		Rand:           rand.New(rand.NewSource(g.Seed)),
+		Previous:       g.Previous,
+		Parent:         g.Parent,
+	}
+}
+
+// Fork returns a deep copy of g for what-if analysis whose Parent is g.
+// Unlike Clone, Settings are copied as well so that the fork shares nothing
+// mutable with g, except for ScoreFunc and ComboBonus. Observers are not
+// inherited.
+func (g *Game) Fork() *Game {
+	f := g.Clone()
+	f.Settings = g.Settings.Clone()
+	f.Parent = g
+	return f
+}
+
+// Replay creates a new game from settings and applies logs in order.
+func Replay(settings *GameSettings, logs []PlayerActionSet) (*Game, error) {
+	return replay(settings, DefaultSeed, logs)
+}
+
+func replay(settings *GameSettings, seed int64, logs []PlayerActionSet) (*Game, error) {
+	g := NewGameWithSeed(settings, seed)
+	for i, pas := range logs {
+		if err := g.ApplyPlayerAction(pas); err != nil {
+			return nil, fmt.Errorf("action log %d: %w", i, err)
+		}
+	}
+	return g, nil
+}
+
+// IsGameOver returns true if the game was over or has no state.
+// Under FirstToPoints, it is also over once a player reached VictoryPoints.
+func (g *Game) IsGameOver() bool {
+	return g.State == nil || g.State.IsGameOver() || g.Settings.reachedVictoryPoints(g.State)
+}
+
+// RemainingRounds returns the number of games left including the current one,
+// or 0 if the game is over.
+func (g *Game) RemainingRounds() uint32 {
+	if g.IsGameOver() || g.State.GameNum > g.Settings.TotalGames {
+		return 0
+	}
+	return g.Settings.TotalGames - g.State.GameNum + 1
+}
+
+// ActionsRemaining returns the number of actions each player can still submit
+// in the current round.
+func (g *Game) ActionsRemaining() map[PlayerID]int {
+	r := make(map[PlayerID]int)
+	if g.State == nil {
+		return r
+	}
+	for _, ps := range g.State.PlayerStates {
+		r[ps.PlayerID] = g.Settings.actionsLeft(ps)
+	}
+	return r
+}
+
+// RoundWinners returns the player who gained the most points in each finished
+// round by the events, keyed by GameNum. Rounds without a single such player
+// are omitted, so a round is a tie if it is finished but not in the map.
+// A round ended by Forfeit, Timeout or Eliminate is not finished. Only the
+// players who submitted actions in the round are compared, so the result for
+// a round does not change when a player leaves later.
+func (g *Game) RoundWinners() map[uint32]PlayerID {
+	gains := make(map[uint32]map[PlayerID]int32)
+	var finished []uint32
+	for _, e := range g.Events {
+		switch e.Type {
+		case PointsAwardedEvent, JustGuardEvent:
+			if gains[e.GameNum] == nil {
+				gains[e.GameNum] = make(map[PlayerID]int32)
+			}
+			gains[e.GameNum][e.PlayerID] += e.Delta
+		case RoundAdvancedEvent:
+			finished = append(finished, e.GameNum)
+		case GameOverEvent:
+			if e.Turn < len(g.ActionLogs) && !g.ActionLogs[e.Turn].outOfBand() {
+				finished = append(finished, e.GameNum)
+			}
+		}
+	}
+	logs := g.LogsByRound()
+	r := make(map[uint32]PlayerID)
+	for _, n := range finished {
+		took := make(map[PlayerID]bool)
+		for _, pas := range logs[n] {
+			if pas.outOfBand() {
+				continue
+			}
+			for _, pa := range pas {
+				took[pa.PlayerID] = true
+			}
+		}
+		var winner PlayerID
+		var most int32
+		found, tie := false, false
+		for _, p := range g.Settings.Players {
+			if !took[p.ID] {
+				continue
+			}
+			if gain := gains[n][p.ID]; !found || gain > most {
+				winner, most, found, tie = p.ID, gain, true, false
+			} else if gain == most {
+				tie = true
+			}
+		}
+		if found && !tie {
+			r[n] = winner
+		}
+	}
+	return r
+}
+
+// TimePerRemainingAction is like PlayerState.TimePerRemainingAction but
+// divides by the actions the player can still submit in the current round,
+// which ActionsPerRound may limit.
+func (g *Game) TimePerRemainingAction(playerID PlayerID) (time.Duration, error) {
+	ps, found := g.State.PlayerStates.Get(playerID)
+	if !found {
+		return 0, &PlayerNotFoundError{PlayerID: playerID}
+	}
+	return ps.timePerAction(g.Settings.actionsLeft(ps)), nil
+}
+
+// ThinkingTimeUsed returns the total ThinkingTimeConsumption of each player in
+// ActionLogs, regardless of increments and byo-yomi.
+func (g *Game) ThinkingTimeUsed() map[PlayerID]time.Duration {
+	r := make(map[PlayerID]time.Duration, len(g.Settings.Players))
+	for _, p := range g.Settings.Players {
+		r[p.ID] = 0
+	}
+	for _, pas := range g.ActionLogs {
+		for _, pa := range pas {
+			r[pa.PlayerID] += pa.ThinkingTimeConsumption
+		}
+	}
+	return r
+}
+
+// TotalActionsPerRound returns the number of the shared actions submitted in
+// each round, which ActionsPerRound caps. See ActionsRemaining for players
+// with their own PlayerActions.
+func (g *Game) TotalActionsPerRound() int {
+	return g.Settings.actionsPerRound(len(g.Settings.Actions))
+}
+
+// ProgressPercent returns the percentage of the actions taken so far out of
+// all the actions given over TotalGames, or 100 if the game is over.
+// Under CooldownRounds, it is the percentage of the finished rounds instead.
+func (g *Game) ProgressPercent() float64 {
+	if g.IsGameOver() {
+		return 100
+	}
+	if g.Settings.CooldownRounds > 0 {
+		return float64(g.State.GameNum-1) / float64(g.Settings.TotalGames) * 100
+	}
+	perRound, used := 0, 0
+	for _, ps := range g.State.PlayerStates {
+		n := g.Settings.actionsPerRoundOf(ps.PlayerID)
+		perRound += n
+		used += n - g.Settings.actionsLeft(ps)
+	}
+	if perRound == 0 {
+		return 0
+	}
+	used += int(g.State.GameNum-1) * perRound
+	return float64(used) / float64(perRound*int(g.Settings.TotalGames)) * 100
+}
+
+// LastAction returns the last entry of ActionLogs.
+func (g *Game) LastAction() (PlayerActionSet, bool) {
+	if len(g.ActionLogs) == 0 {
+		return nil, false
+	}
+	return g.ActionLogs[len(g.ActionLogs)-1], true
+}
+
+// LastActionFor returns the action of the player in the last entry of
+// ActionLogs.
+func (g *Game) LastActionFor(playerID PlayerID) (*PlayerAction, bool) {
+	pas, ok := g.LastAction()
+	if !ok {
+		return nil, false
+	}
+	return pas.Get(playerID)
 }
 
 // ApplyPlayerAction will mutate ActionLogs and State.
 func (g *Game) ApplyPlayerAction(playerActions PlayerActionSet) error {
-	if len(g.Settings.Players) != len(playerActions) {
-		return errors.New("invalid size of player action set")
+	state, events, err := g.nextState(g.State, playerActions)
+	if err != nil {
+		return err
 	}
-	if g.State.GameNum == GameOver {
-		return errors.New("game was over")
+	for i := range events {
+		events[i].Turn = len(g.ActionLogs)
 	}
-	state := g.State.Clone()
+	prev := g.State
+	g.State = state
+	g.ActionLogs = append(g.ActionLogs, playerActions)
+	g.Events = append(g.Events, events...)
+	g.notify(prev)
+	return nil
+}
+
+// ApplyPlayerActions applies sets in order by ApplyPlayerAction and returns
+// the number of the applied sets. It stops at the first failure, leaving the
+// game as it was after the last applied set. Use Clone beforehand to discard
+// partial results.
+func (g *Game) ApplyPlayerActions(sets []PlayerActionSet) (applied int, err error) {
+	for i, pas := range sets {
+		if err := g.ApplyPlayerAction(pas); err != nil {
+			return i, fmt.Errorf("action set %d: %w", i, err)
+		}
+	}
+	return len(sets), nil
+}
+
+// CanApplyPlayerAction returns the error which ApplyPlayerAction would return
+// for playerActions, without mutating the game.
+func (g *Game) CanApplyPlayerAction(playerActions PlayerActionSet) error {
+	_, _, err := g.nextState(g.State, playerActions)
+	return err
+}
+
+// Undo rolls back the last applied player action set by replaying the
+// remaining ActionLogs from the initial state.
+func (g *Game) Undo() error {
+	if len(g.ActionLogs) == 0 {
+		return errors.New("no action to undo")
+	}
+	r, err := replay(g.Settings, g.Seed, g.ActionLogs[:len(g.ActionLogs)-1])
+	if err != nil {
+		return err
+	}
+	g.State = r.State
+	g.ActionLogs = r.ActionLogs
+	g.Events = r.Events
+	g.Rand = r.Rand
+	return nil
+}
+
+// PreviewOutcome returns the points each player would gain if pas were
+// applied, without mutating the game. Errors are the same as
+// CanApplyPlayerAction.
+func (g *Game) PreviewOutcome(pas PlayerActionSet) (map[PlayerID]int32, error) {
+	state, _, err := g.nextState(g.State, pas)
+	if err != nil {
+		return nil, err
+	}
+	r := make(map[PlayerID]int32, len(state.PlayerStates))
+	for _, ps := range state.PlayerStates {
+		prev, _ := g.State.PlayerStates.Get(ps.PlayerID)
+		r[ps.PlayerID] = ps.Points - prev.Points
+	}
+	return r, nil
+}
+
+// ValidateActions checks that every action in pas is available for its player
+// and targets a player of the game. Thinking time is not checked.
+func (g *Game) ValidateActions(pas PlayerActionSet) error {
+	for _, pa := range pas {
+		ps, found := g.State.PlayerStates.Get(pa.PlayerID)
+		if !found {
+			return &PlayerNotFoundError{PlayerID: pa.PlayerID}
+		}
+		if pa.Action.Type == Pass && g.Settings.AllowPass {
+			continue
+		}
+		if !ps.AvailableActions().Contains(pa.Action) {
+			return fmt.Errorf("player (id: %d): %w", pa.PlayerID, ErrActionUnavailable)
+		}
+		if _, found := g.Settings.Players.Get(pa.TargetPlayerID); !found {
+			return &PlayerNotFoundError{PlayerID: pa.TargetPlayerID}
+		}
+		if pa.Action.Type.targetsPlayer() && !g.Settings.FriendlyFire && g.Settings.isTeammate(pa.PlayerID, pa.TargetPlayerID) {
+			return ErrFriendlyFire
+		}
+		if pa.Action.Type.targetsPlayer() && !g.Settings.AllowSelfTarget && pa.PlayerID == pa.TargetPlayerID {
+			return ErrSelfTarget
+		}
+		if tps, found := g.State.PlayerStates.Get(pa.TargetPlayerID); found && tps.Status == Eliminated && pa.Action.Type.targetsPlayer() {
+			return fmt.Errorf("player (id: %d): %w", pa.TargetPlayerID, ErrPlayerEliminated)
+		}
+	}
+	return nil
+}
+
+// ValidateState reports all the inconsistencies of State with Settings, e.g.
+// of a corrupted saved game.
+func (g *Game) ValidateState() error {
+	if g.Settings == nil || g.State == nil {
+		return errors.New("settings or state not found")
+	}
+	var errs []error
+	if g.State.GameNum > g.Settings.TotalGames {
+		errs = append(errs, fmt.Errorf("game num out of range: %d", g.State.GameNum))
+	}
+	if len(g.State.PlayerStates) != len(g.Settings.Players) {
+		errs = append(errs, errors.New("player states do not match players"))
+	}
+	seen := make(map[PlayerID]bool, len(g.State.PlayerStates))
+	for _, ps := range g.State.PlayerStates {
+		if seen[ps.PlayerID] {
+			errs = append(errs, fmt.Errorf("player (id: %d) state is duplicated", ps.PlayerID))
+		}
+		seen[ps.PlayerID] = true
+		if _, found := g.Settings.Players.Get(ps.PlayerID); !found {
+			errs = append(errs, &PlayerNotFoundError{PlayerID: ps.PlayerID})
+			continue
+		}
+		if ps.Status < Playing || ps.Status > Eliminated {
+			errs = append(errs, fmt.Errorf("player (id: %d) status is invalid: %d", ps.PlayerID, ps.Status))
+		}
+		if ps.ThinkingTime < 0 {
+			errs = append(errs, fmt.Errorf("player (id: %d) thinking time is negative", ps.PlayerID))
+		}
+		if ps.ByoYomiPeriods < 0 || ps.ByoYomiPeriods > g.Settings.ByoYomiPeriods {
+			errs = append(errs, fmt.Errorf("player (id: %d) byo-yomi periods out of range: %d", ps.PlayerID, ps.ByoYomiPeriods))
+		}
+		held := append(ps.AvailableActions().Clone(), ps.UsedActions...)
+		for _, c := range ps.Cooldowns {
+			held = append(held, c.Action)
+		}
+		pool := g.Settings.actionsOf(ps.PlayerID)
+		for _, a := range held {
+			if held.countOf(a) > pool.countOf(a) {
+				errs = append(errs, fmt.Errorf("player (id: %d) has unknown action: %v", ps.PlayerID, a))
+				break
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// LogsByRound groups ActionLogs by the game number in which they were applied.
+// The action set which ended the game belongs to the last game, not GameOver.
+// Logs after an action set which cannot be replayed are omitted.
+func (g *Game) LogsByRound() map[uint32][]PlayerActionSet {
+	r := make(map[uint32][]PlayerActionSet)
+	state := NewGameState(g.Settings)
+	for _, pas := range g.ActionLogs {
+		r[state.GameNum] = append(r[state.GameNum], pas)
+		next, _, err := g.nextState(state, pas)
+		if err != nil {
+			break
+		}
+		state = next
+	}
+	return r
+}
+
+// nextState returns the state resulting from applying playerActions to state
+// and the events which happened. state is not mutated.
+func (g *Game) nextState(state *GameState, playerActions PlayerActionSet) (*GameState, []GameEvent, error) {
+	outOfBand := playerActions.outOfBand()
+	if state == nil || state.IsGameOver() || g.Settings.reachedVictoryPoints(state) {
+		return nil, nil, ErrGameOver
+	}
+	acting := g.actingPlayers(state)
+	if !outOfBand && len(acting) != len(playerActions) {
+		return nil, nil, ErrInvalidActionSetSize
+	}
+	if !outOfBand {
+		if err := playerActions.Validate(acting); err != nil {
+			return nil, nil, err
+		}
+	}
+	state = state.Clone()
+	var events []GameEvent
+	event := func(e GameEvent) {
+		e.GameNum = state.GameNum
+		events = append(events, e)
+	}
+	if outOfBand {
+		ps, found := state.PlayerStates.Get(playerActions[0].PlayerID)
+		if !found {
+			return nil, nil, &PlayerNotFoundError{PlayerID: playerActions[0].PlayerID}
+		}
+		if ps.Status != Playing {
+			return nil, nil, fmt.Errorf("player (id: %d): %w", ps.PlayerID, ErrPlayerEliminated)
+		}
+		if playerActions.IsElimination() {
+			ps.Status = Eliminated
+			event(GameEvent{Type: EliminatedEvent, PlayerID: ps.PlayerID})
+			if len(state.ActivePlayers()) >= 2 {
+				return state, events, nil
+			}
+			event(GameEvent{Type: GameOverEvent})
+			state.GameNum = GameOver
+			return state, events, nil
+		}
+		if playerActions.IsTimeout() {
+			ps.Status = TimedOut
+			ps.ThinkingTime = 0
+		} else {
+			ps.Status = Forfeited
+		}
+		event(GameEvent{Type: GameOverEvent, PlayerID: ps.PlayerID})
+		state.GameNum = GameOver
+		return state, events, nil
+	}
+	state.Turns++
+	roundOver := false
+	var swaps PlayerActionSet
 	for _, pa := range playerActions {
 		ps, found := state.PlayerStates.Get(pa.PlayerID)
 		if !found {
-			return fmt.Errorf("player (id: %d) state not found", pa.PlayerID)
+			return nil, nil, &PlayerNotFoundError{PlayerID: pa.PlayerID}
+		}
+		if pa.Action.Type.targetsPlayer() {
+			if !g.Settings.FriendlyFire && g.Settings.isTeammate(pa.PlayerID, pa.TargetPlayerID) {
+				return nil, nil, ErrFriendlyFire
+			}
+			if !g.Settings.AllowSelfTarget && pa.PlayerID == pa.TargetPlayerID {
+				return nil, nil, ErrSelfTarget
+			}
+			if tps, found := state.PlayerStates.Get(pa.TargetPlayerID); found && tps.Status == Eliminated {
+				return nil, nil, fmt.Errorf("player (id: %d): %w", pa.TargetPlayerID, ErrPlayerEliminated)
+			}
+		}
+		if pa.Action.Type == Swap {
+			if _, found := state.PlayerStates.Get(pa.TargetPlayerID); !found {
+				return nil, nil, &PlayerNotFoundError{PlayerID: pa.TargetPlayerID}
+			}
+			swaps = append(swaps, pa)
 		}
 		// Update `ps.Points`.
-		switch pa.Action.Type {
-		case Attack:
-			tpa, found := playerActions.Get(pa.TargetPlayerID)
+		// Each attack is resolved independently against the single action
+		// submitted by its target. Simultaneous attacks on one defender
+		// therefore stack: each of them is compared with the same defence,
+		// and each just guard credits the defender with JustGuardPoint again.
+		if pa.Action.Type == Attack {
+			tps, found := state.PlayerStates.Get(pa.TargetPlayerID)
 			if !found {
-				return fmt.Errorf("player (id: %d) action not found", pa.TargetPlayerID)
-			}
-			switch tpa.Action.Type {
-			case Defence:
-				points := pa.Action.Level.Sub(tpa.Action.Level)
-				if points > 0 {
-					ps.Points += int32(points)
-				} else if points == 0 {
-					tps, found := state.PlayerStates.Get(pa.TargetPlayerID)
-					if !found {
-						return fmt.Errorf("player (id: %d) state not found", pa.PlayerID)
-					}
-					tps.Points += g.Settings.JustGuardPoint
+				return nil, nil, &PlayerNotFoundError{PlayerID: pa.TargetPlayerID}
+			}
+			// A player waiting for the others under AllPlayersEmpty cannot
+			// defend, as if it passed.
+			tpa := &PlayerAction{PlayerID: tps.PlayerID, Action: Action{Type: Pass}}
+			if _, acts := acting.Get(tps.PlayerID); acts {
+				tpa, found = playerActions.Get(pa.TargetPlayerID)
+				if !found {
+					return nil, nil, &PlayerNotFoundError{PlayerID: pa.TargetPlayerID}
 				}
-			default:
-				ps.Points += int32(pa.Action.Level)
 			}
-		default:
-			// do nothing
+			attackerDelta, defenderDelta := g.Settings.score(pa.Action, tpa.Action)
+			// An attack scoring points is a hit and extends the streak.
+			// Any other attack, including one stopped by a just guard,
+			// resets it.
+			if attackerDelta > 0 {
+				ps.Streak++
+				if g.Settings.ComboBonus != nil {
+					attackerDelta += g.Settings.ComboBonus(ps.Streak)
+				}
+			} else {
+				ps.Streak = 0
+			}
+			points := ps.Points
+			ps.Points = g.Settings.clampPoints(ps.Points + attackerDelta)
+			if d := ps.Points - points; d != 0 {
+				event(GameEvent{Type: PointsAwardedEvent, PlayerID: ps.PlayerID, TargetPlayerID: tps.PlayerID, Delta: d})
+			}
+			points = tps.Points
+			tps.Points = g.Settings.clampPoints(tps.Points + defenderDelta)
+			if tpa.Action.Type.defends() && tpa.Action.Level == pa.Action.Level {
+				event(GameEvent{Type: JustGuardEvent, PlayerID: tps.PlayerID, TargetPlayerID: ps.PlayerID, Delta: tps.Points - points})
+			} else if d := tps.Points - points; d != 0 {
+				event(GameEvent{Type: PointsAwardedEvent, PlayerID: tps.PlayerID, TargetPlayerID: ps.PlayerID, Delta: d})
+			}
+		}
+		if pa.Action.Type.defends() || pa.Action.Type == Swap {
+			ps.Streak = 0
 		}
 		// Update `ps.Actions`.
-		{
-			as, ok := ps.Actions.Remove(pa.Action)
+		if pa.Action.Type != Pass || !g.Settings.AllowPass {
+			as, ok := ps.AvailableActions().Remove(pa.Action)
 			if !ok {
-				return errors.New("unavailable action")
+				return nil, nil, ErrActionUnavailable
 			}
-			if len(as) == 0 {
-				state.GameNum++
-				if state.GameNum > g.Settings.TotalGames {
-					state.GameNum = GameOver
-				} else {
-					ps.Actions = g.Settings.Actions.Clone()
-				}
+			if g.Settings.TrackUsedActions {
+				ps.UsedActions = append(ps.UsedActions, pa.Action)
 			} else {
 				ps.Actions = as
 			}
+			if g.Settings.CooldownRounds > 0 {
+				ps.Cooldowns = append(ps.Cooldowns, Cooldown{Action: pa.Action, Rounds: g.Settings.CooldownRounds})
+				roundOver = true
+			} else if g.Settings.actionsLeft(ps) == 0 && g.Settings.RoundAdvancePolicy == AnyPlayerEmpty {
+				roundOver = true
+			}
 		}
 		// Update `ps.ThinkingTime`.
-		{
-			if ps.ThinkingTime < pa.ThinkingTimeConsumption {
-				return errors.New("over thinking time")
+		if err := g.Settings.checkMinConsumption(ps, pa.ThinkingTimeConsumption); err != nil {
+			return nil, nil, err
+		}
+		if err := g.Settings.consumeThinkingTime(ps, pa.ThinkingTimeConsumption); err != nil {
+			return nil, nil, err
+		}
+	}
+	// Swaps are applied in order after all the attacks are scored, so swaps
+	// of two players with each other cancel out.
+	for _, pa := range swaps {
+		ps, _ := state.PlayerStates.Get(pa.PlayerID)
+		tps, _ := state.PlayerStates.Get(pa.TargetPlayerID)
+		if d := tps.Points - ps.Points; d != 0 {
+			event(GameEvent{Type: PointsAwardedEvent, PlayerID: ps.PlayerID, TargetPlayerID: tps.PlayerID, Delta: d})
+			event(GameEvent{Type: PointsAwardedEvent, PlayerID: tps.PlayerID, TargetPlayerID: ps.PlayerID, Delta: -d})
+		}
+		ps.Points, tps.Points = tps.Points, ps.Points
+	}
+	if g.Settings.EliminateAtMinPoints && g.Settings.MinPoints != nil {
+		for _, ps := range state.PlayerStates {
+			if ps.Status == Playing && ps.Points <= *g.Settings.MinPoints {
+				ps.Status = Eliminated
+				event(GameEvent{Type: EliminatedEvent, PlayerID: ps.PlayerID})
 			}
-			ps.ThinkingTime -= pa.ThinkingTimeConsumption
-			ps.ThinkingTime += g.Settings.ThinkingTimeIncrement
+		}
+		if len(state.ActivePlayers()) < 2 {
+			event(GameEvent{Type: GameOverEvent})
+			state.GameNum = GameOver
+			return state, events, nil
 		}
 	}
-	g.State = state
-	g.ActionLogs = append(g.ActionLogs, playerActions)
+	if g.Settings.RoundAdvancePolicy == AllPlayersEmpty && len(g.actingPlayers(state)) == 0 {
+		roundOver = true
+	}
+	if g.Settings.EndOnMaxPoints && g.Settings.MaxPoints != nil {
+		for _, ps := range state.PlayerStates {
+			if ps.Points >= *g.Settings.MaxPoints {
+				if g.Settings.ResetPointsEachRound {
+					state.tallyRound(g.Settings)
+				}
+				event(GameEvent{Type: GameOverEvent})
+				state.GameNum = GameOver
+				return state, events, nil
+			}
+		}
+	}
+	if g.Settings.reachedVictoryPoints(state) {
+		if g.Settings.ResetPointsEachRound {
+			state.tallyRound(g.Settings)
+		}
+		event(GameEvent{Type: GameOverEvent})
+		state.GameNum = GameOver
+		return state, events, nil
+	}
+	if g.Settings.MaxRounds > 0 && state.Turns >= g.Settings.MaxRounds {
+		if g.Settings.ResetPointsEachRound {
+			state.tallyRound(g.Settings)
+		}
+		event(GameEvent{Type: GameOverEvent})
+		state.GameNum = GameOver
+		return state, events, nil
+	}
+	// Advance the round once per action set, even if several players used up
+	// their actions at the same time, and give everyone a fresh action list
+	// or, under CooldownRounds, the actions whose cooldown expired.
+	if roundOver {
+		if g.Settings.ResetPointsEachRound {
+			state.tallyRound(g.Settings)
+		}
+		if state.GameNum >= g.Settings.TotalGames {
+			event(GameEvent{Type: GameOverEvent})
+			state.GameNum = GameOver
+		} else {
+			event(GameEvent{Type: RoundAdvancedEvent})
+			state.GameNum++
+			for _, ps := range state.PlayerStates {
+				ps.PointsHistory = append(ps.PointsHistory, ps.Points)
+				if g.Settings.CooldownRounds > 0 {
+					ps.advanceCooldowns()
+				} else {
+					ps.Actions = g.Settings.actionsOf(ps.PlayerID).Clone()
+					ps.UsedActions = nil
+				}
+				if g.Settings.ResetPointsEachRound {
+					ps.Points = g.Settings.InitialPoints[ps.PlayerID]
+				}
+			}
+		}
+	}
+	return state, events, nil
+}
+
+// Timeout marks the player as timed out and ends the game.
+// The other players compete for the win by their points.
+// It is recorded in ActionLogs as a set which satisfies IsTimeout.
+// An eliminated player cannot time out.
+func (g *Game) Timeout(playerID PlayerID) error {
+	if g.IsGameOver() {
+		return ErrGameOver
+	}
+	return g.ApplyPlayerAction(PlayerActionSet{
+		{PlayerID: playerID, TargetPlayerID: playerID, Action: Action{Type: Timeout}},
+	})
+}
+
+// Eliminate removes the player from the game, which the others continue.
+// It is recorded in ActionLogs as a set which satisfies IsElimination.
+// The game is over once fewer than two players are active. A pending action
+// of the player is discarded, and those of the others are applied if no other
+// player is pending.
+func (g *Game) Eliminate(playerID PlayerID) error {
+	if g.IsGameOver() {
+		return ErrGameOver
+	}
+	err := g.ApplyPlayerAction(PlayerActionSet{
+		{PlayerID: playerID, TargetPlayerID: playerID, Action: Action{Type: Eliminate}},
+	})
+	if err != nil {
+		return err
+	}
+	if g.IsGameOver() {
+		g.PendingActions = nil
+		return nil
+	}
+	pending := make(PlayerActionSet, 0, len(g.PendingActions))
+	for _, pa := range g.PendingActions {
+		if pa.PlayerID != playerID {
+			pending = append(pending, pa)
+		}
+	}
+	g.PendingActions = pending
+	if len(pending) > 0 && len(g.PendingPlayers()) == 0 {
+		g.PendingActions = nil
+		return g.ApplyPlayerAction(pending)
+	}
 	return nil
 }
+
+// Forfeit ends the game as the player resigns.
+// It is recorded in ActionLogs as a set which satisfies IsForfeit.
+// An eliminated player cannot forfeit.
+func (g *Game) Forfeit(playerID PlayerID) error {
+	return g.ApplyPlayerAction(PlayerActionSet{
+		{PlayerID: playerID, TargetPlayerID: playerID, Action: Action{Type: Forfeit}},
+	})
+}
+
+// GetWinner returns the player who has the highest points, ignoring players
+// who timed out, forfeited or were eliminated. It returns false if the game
+// is not over yet or the highest points are tied after TieBreakers.
+func (g *Game) GetWinner() (*Player, bool) {
+	if !g.IsGameOver() {
+		return nil, false
+	}
+	leaders := g.leaders()
+	if len(leaders) != 1 {
+		return nil, false
+	}
+	return g.Settings.Players.Get(leaders[0].PlayerID)
+}
+
+type PlayerScore struct {
+	PlayerID     PlayerID      `json:"playerId"`
+	Name         string        `json:"name"`
+	Points       int32         `json:"points"`
+	ThinkingTime time.Duration `json:"thinkingTime"`
+}
+
+// Scores returns the scores of all players from the best points under the
+// victory condition. Players with the same points are ordered by TieBreakers
+// and then by ID.
+func (g *Game) Scores() []PlayerScore {
+	states := append(PlayerStateSet(nil), g.State.PlayerStates...)
+	sort.SliceStable(states, func(i, j int) bool {
+		if c := g.Settings.comparePoints(states[i].Points, states[j].Points); c != 0 {
+			return c > 0
+		}
+		if c := g.compareTieBreakers(states[i], states[j]); c != 0 {
+			return c > 0
+		}
+		return states[i].PlayerID < states[j].PlayerID
+	})
+	r := make([]PlayerScore, 0, len(states))
+	for _, ps := range states {
+		score := PlayerScore{
+			PlayerID:     ps.PlayerID,
+			Points:       ps.Points,
+			ThinkingTime: ps.ThinkingTime,
+		}
+		if p, found := g.Settings.Players.Get(ps.PlayerID); found {
+			score.Name = p.Name
+		}
+		r = append(r, score)
+	}
+	return r
+}
+
+// IsDraw returns true if the game is over and two or more players share the
+// highest points after TieBreakers.
+func (g *Game) IsDraw() bool {
+	if !g.IsGameOver() {
+		return false
+	}
+	return len(g.leaders()) > 1
+}
+
+// GameResult is the summary of a finished game.
+type GameResult struct {
+	// Winner is nil on a draw.
+	Winner *Player `json:"winner,omitempty"`
+	Draw   bool    `json:"draw"`
+	// Scores are ordered as Game.Scores and include the remaining clocks.
+	Scores []PlayerScore `json:"scores"`
+	// Rounds is the number of the started rounds.
+	Rounds uint32 `json:"rounds"`
+	// TotalActions is the number of the actions played in ActionLogs,
+	// excluding the entries recorded by Forfeit and the like.
+	TotalActions int `json:"totalActions"`
+}
+
+// Summary returns the result of g. It fails if the game is not over yet.
+func (g *Game) Summary() (*GameResult, error) {
+	if !g.IsGameOver() {
+		return nil, errors.New("game is not over yet")
+	}
+	r := &GameResult{
+		Draw:   g.IsDraw(),
+		Scores: g.Scores(),
+		Rounds: 1,
+	}
+	if p, ok := g.GetWinner(); ok {
+		copied := *p
+		r.Winner = &copied
+	}
+	for _, e := range g.Events {
+		if e.Type == RoundAdvancedEvent {
+			r.Rounds++
+		}
+	}
+	for _, pas := range g.ActionLogs {
+		if !pas.outOfBand() {
+			r.TotalActions += len(pas)
+		}
+	}
+	return r, nil
+}
+
+// SpectatorView returns a copy of the current state to be shown to forPlayer.
+// Actions of the players other than forPlayer are redacted to an empty list,
+// while their UsedActions are kept since they were played in public.
+// If forPlayer is nil, nothing is redacted.
+// PendingActions are not a part of the state and thus never included.
+func (g *Game) SpectatorView(forPlayer *PlayerID) *GameState {
+	if g.State == nil {
+		return nil
+	}
+	state := g.State.Clone()
+	if forPlayer == nil {
+		return state
+	}
+	for _, ps := range state.PlayerStates {
+		if ps.PlayerID != *forPlayer {
+			ps.Actions = ActionList{}
+		}
+	}
+	return state
+}
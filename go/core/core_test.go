@@ -1 +1,2250 @@
-// TODO
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestSettings() *GameSettings {
+	return &GameSettings{
+		Version: Version,
+		Players: PlayerSet{
+			{ID: 1, Name: "P1"},
+			{ID: 2, Name: "P2"},
+		},
+		TotalGames:            1,
+		InitialThinkingTime:   10 * time.Second,
+		ThinkingTimeIncrement: 5 * time.Second,
+		Actions: ActionList{
+			{Attack, 1}, {Attack, 2}, {Attack, 3},
+			{Defence, 1}, {Defence, 2}, {Defence, 3},
+		},
+		JustGuardPoint: 3,
+	}
+}
+
+func TestGetWinner(t *testing.T) {
+	g := NewGame(newTestSettings())
+	if _, ok := g.GetWinner(); ok {
+		t.Fatal("winner found before game over")
+	}
+	g.State.GameNum = GameOver
+	g.State.PlayerStates[0].Points = 3
+	g.State.PlayerStates[1].Points = 7
+	p, ok := g.GetWinner()
+	if !ok || p.ID != 2 {
+		t.Fatalf("unexpected winner: %v, %v", p, ok)
+	}
+	g.State.PlayerStates[0].Points = 7
+	if _, ok := g.GetWinner(); ok {
+		t.Fatal("winner found on tie")
+	}
+}
+
+func TestIsDraw(t *testing.T) {
+	g := NewGame(newTestSettings())
+	if g.IsDraw() {
+		t.Fatal("draw before game over")
+	}
+	g.State.GameNum = GameOver
+	g.State.PlayerStates[0].Points = 7
+	g.State.PlayerStates[1].Points = 7
+	if !g.IsDraw() {
+		t.Fatal("tie not detected")
+	}
+	g.State.PlayerStates[1].Points = 3
+	if g.IsDraw() {
+		t.Fatal("draw on decisive result")
+	}
+
+	settings := newTestSettings()
+	settings.Players = settings.Players[:1]
+	g = NewGame(settings)
+	g.State.GameNum = GameOver
+	if g.IsDraw() {
+		t.Fatal("draw in single player game")
+	}
+}
+
+func TestIsGameOver(t *testing.T) {
+	g := NewGame(newTestSettings())
+	if g.IsGameOver() || g.State.IsGameOver() {
+		t.Fatal("game over at start")
+	}
+	g.State.GameNum = GameOver
+	if !g.IsGameOver() || !g.State.IsGameOver() {
+		t.Fatal("game over not detected")
+	}
+	if !(&Game{}).IsGameOver() {
+		t.Fatal("game without state is not over")
+	}
+}
+
+func TestActionListRemove(t *testing.T) {
+	al := ActionList{{Attack, 1}, {Attack, 2}, {Defence, 1}}
+	r, ok := al.Remove(Action{Attack, 2})
+	if !ok || len(r) != 2 || r[0] != (Action{Attack, 1}) || r[1] != (Action{Defence, 1}) {
+		t.Fatalf("unexpected result: %v, %v", r, ok)
+	}
+	if len(al) != 3 || al[1] != (Action{Attack, 2}) || al[2] != (Action{Defence, 1}) {
+		t.Fatalf("receiver was mutated: %v", al)
+	}
+	if _, ok := al.Remove(Action{Defence, 3}); ok {
+		t.Fatal("removed missing action")
+	}
+
+	s := NewGameState(newTestSettings())
+	c := s.Clone()
+	c.PlayerStates[0].Actions, _ = c.PlayerStates[0].Actions.Remove(Action{Attack, 1})
+	if len(s.PlayerStates[0].Actions) != 6 || s.PlayerStates[0].Actions[0] != (Action{Attack, 1}) {
+		t.Fatalf("original state was mutated: %v", s.PlayerStates[0].Actions)
+	}
+}
+
+func TestCanApplyPlayerAction(t *testing.T) {
+	g := NewGame(newTestSettings())
+	valid := PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 1}},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 3}},
+	}
+	if err := g.CanApplyPlayerAction(valid); err != nil {
+		t.Fatal(err)
+	}
+	invalids := []PlayerActionSet{
+		valid[:1],
+		{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 4}},
+			{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 3}},
+		},
+		{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 1}, ThinkingTimeConsumption: time.Minute},
+			{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 3}},
+		},
+		{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 1}},
+			{PlayerID: 3, TargetPlayerID: 1, Action: Action{Defence, 3}},
+		},
+	}
+	for i, pas := range invalids {
+		if err := g.CanApplyPlayerAction(pas); err == nil {
+			t.Errorf("%d: invalid action set accepted", i)
+		}
+	}
+	if g.State.GameNum != 1 || len(g.ActionLogs) != 0 ||
+		len(g.State.PlayerStates[0].Actions) != 6 || g.State.PlayerStates[0].Points != 0 {
+		t.Fatal("game was mutated")
+	}
+	g.State.GameNum = GameOver
+	if err := g.CanApplyPlayerAction(valid); err == nil {
+		t.Fatal("action accepted after game over")
+	}
+}
+
+func TestApplyPlayerActions(t *testing.T) {
+	sets := []PlayerActionSet{
+		{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 1}},
+			{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 3}},
+		},
+		{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 2}},
+			{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 2}},
+		},
+	}
+	g := NewGame(newTestSettings())
+	if n, err := g.ApplyPlayerActions(sets); n != 2 || err != nil {
+		t.Fatalf("unexpected result: %d, %v", n, err)
+	}
+	assertPoints(t, g, 0, 3)
+
+	g = NewGame(newTestSettings())
+	n, err := g.ApplyPlayerActions(append(sets, sets[0], sets[1]))
+	if n != 2 || !errors.Is(err, ErrActionUnavailable) {
+		t.Fatalf("unexpected result: %d, %v", n, err)
+	}
+	if len(g.ActionLogs) != 2 {
+		t.Errorf("applied sets should be kept: %d", len(g.ActionLogs))
+	}
+	assertPoints(t, g, 0, 3)
+}
+
+func TestSelfTarget(t *testing.T) {
+	pas := PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 1, Action: Action{Attack, 2}},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 1}},
+	}
+	g := NewGame(newTestSettings())
+	if err := g.ValidateActions(pas); !errors.Is(err, ErrSelfTarget) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := g.ApplyPlayerAction(pas); !errors.Is(err, ErrSelfTarget) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	settings := newTestSettings()
+	settings.AllowSelfTarget = true
+	g = NewGame(settings)
+	if err := g.ApplyPlayerAction(pas); err != nil {
+		t.Fatal(err)
+	}
+	assertPoints(t, g, 2, 0)
+}
+
+func TestUndo(t *testing.T) {
+	g := NewGame(newTestSettings())
+	if err := g.Undo(); err == nil {
+		t.Fatal("undo succeeded without actions")
+	}
+	sets := []PlayerActionSet{
+		{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 1}, ThinkingTimeConsumption: time.Second},
+			{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 3}, ThinkingTimeConsumption: 2 * time.Second},
+		},
+		{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Defence, 1}, ThinkingTimeConsumption: 3 * time.Second},
+			{PlayerID: 2, TargetPlayerID: 1, Action: Action{Attack, 2}, ThinkingTimeConsumption: 4 * time.Second},
+		},
+		{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 2}, ThinkingTimeConsumption: 5 * time.Second},
+			{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 2}, ThinkingTimeConsumption: 6 * time.Second},
+		},
+	}
+	var snapshot *GameState
+	for _, pas := range sets {
+		snapshot = g.State.Clone()
+		if err := g.ApplyPlayerAction(pas); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := g.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(g.State, snapshot) {
+		t.Fatalf("unexpected state: %+v, want %+v", g.State, snapshot)
+	}
+	if len(g.ActionLogs) != len(sets)-1 {
+		t.Fatalf("unexpected log size: %d", len(g.ActionLogs))
+	}
+}
+
+func TestReplay(t *testing.T) {
+	settings := newTestSettings()
+	logs := []PlayerActionSet{
+		{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 3}},
+			{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 1}},
+		},
+		{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Defence, 2}},
+			{PlayerID: 2, TargetPlayerID: 1, Action: Action{Attack, 2}},
+		},
+	}
+	g, err := Replay(settings, logs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.State.PlayerStates[0].Points != 5 || g.State.PlayerStates[1].Points != 0 || len(g.ActionLogs) != 2 {
+		t.Fatalf("unexpected game: %+v", g.State.PlayerStates)
+	}
+	if _, err := Replay(settings, append(logs, logs[0])); err == nil {
+		t.Fatal("replayed unavailable action")
+	}
+}
+
+func TestApplyPlayerActionRounds(t *testing.T) {
+	settings := newTestSettings()
+	settings.TotalGames = 2
+	g := NewGame(settings)
+	for round := uint32(1); round <= 2; round++ {
+		for i, a := range settings.Actions {
+			if g.State.GameNum != round {
+				t.Fatalf("unexpected game num: %d, want %d", g.State.GameNum, round)
+			}
+			pas := PlayerActionSet{
+				{PlayerID: 1, TargetPlayerID: 2, Action: a},
+				{PlayerID: 2, TargetPlayerID: 1, Action: settings.Actions[len(settings.Actions)-1-i]},
+			}
+			if err := g.ApplyPlayerAction(pas); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if !g.IsGameOver() {
+		t.Fatalf("game is not over: %d", g.State.GameNum)
+	}
+}
+
+func TestApplyPlayerActionMultiplayer(t *testing.T) {
+	settings := newTestSettings()
+	settings.Players = append(settings.Players, &Player{ID: 3, Name: "P3"})
+	g := NewGame(settings)
+	err := g.ApplyPlayerAction(PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 3, Action: Action{Attack, 2}},
+		{PlayerID: 2, TargetPlayerID: 3, Action: Action{Attack, 3}},
+		{PlayerID: 3, TargetPlayerID: 1, Action: Action{Defence, 2}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertPoints(t, g, 0, 1, 3)
+
+	settings = newTestSettings()
+	settings.Players = append(settings.Players, &Player{ID: 3, Name: "P3"}, &Player{ID: 4, Name: "P4"})
+	g = NewGame(settings)
+	err = g.ApplyPlayerAction(PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 4, Action: Action{Attack, 1}},
+		{PlayerID: 2, TargetPlayerID: 4, Action: Action{Attack, 1}},
+		{PlayerID: 3, TargetPlayerID: 1, Action: Action{Attack, 3}},
+		{PlayerID: 4, TargetPlayerID: 1, Action: Action{Defence, 1}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertPoints(t, g, 0, 0, 3, 6)
+}
+
+func assertPoints(t *testing.T, g *Game, points ...int32) {
+	t.Helper()
+	for i, p := range points {
+		if g.State.PlayerStates[i].Points != p {
+			t.Errorf("player %d: unexpected points: %d, want %d", g.State.PlayerStates[i].PlayerID, g.State.PlayerStates[i].Points, p)
+		}
+	}
+}
+
+func TestGameSettingsValidate(t *testing.T) {
+	if err := newTestSettings().Validate(); err != nil {
+		t.Fatal(err)
+	}
+	settings := newTestSettings()
+	settings.Players = settings.Players[:1]
+	settings.TotalGames = 0
+	settings.InitialThinkingTime = -1
+	settings.Actions = nil
+	err := settings.Validate()
+	if err == nil {
+		t.Fatal("invalid settings accepted")
+	}
+	if n := strings.Count(err.Error(), "\n") + 1; n != 4 {
+		t.Fatalf("unexpected number of errors: %d: %v", n, err)
+	}
+	if _, err := NewGameChecked(settings); err == nil {
+		t.Fatal("game created with invalid settings")
+	}
+	if _, err := NewGameChecked(newTestSettings()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPlayerSetHasDuplicateIDs(t *testing.T) {
+	settings := newTestSettings()
+	if settings.Players.HasDuplicateIDs() {
+		t.Fatal("unique ids reported as duplicate")
+	}
+	settings.Players[1].ID = 1
+	if !settings.Players.HasDuplicateIDs() {
+		t.Fatal("duplicate ids not detected")
+	}
+	if err := settings.Validate(); err == nil || !strings.Contains(err.Error(), "duplicate player id: 1") {
+		t.Fatalf("duplicate ids accepted: %v", err)
+	}
+	if g, err := NewGameChecked(settings); err == nil {
+		t.Fatalf("game created for duplicate ids: %+v", g)
+	}
+}
+
+func TestScores(t *testing.T) {
+	settings := newTestSettings()
+	settings.Players = append(settings.Players, &Player{ID: 3, Name: "P3"})
+	g := NewGame(settings)
+	g.State.PlayerStates[0].Points = 2
+	g.State.PlayerStates[1].Points = 5
+	g.State.PlayerStates[2].Points = 2
+	g.State.PlayerStates[2].ThinkingTime = time.Second
+	scores := g.Scores()
+	want := []PlayerScore{
+		{PlayerID: 2, Name: "P2", Points: 5, ThinkingTime: 10 * time.Second},
+		{PlayerID: 1, Name: "P1", Points: 2, ThinkingTime: 10 * time.Second},
+		{PlayerID: 3, Name: "P3", Points: 2, ThinkingTime: time.Second},
+	}
+	if !reflect.DeepEqual(scores, want) {
+		t.Fatalf("unexpected scores: %+v", scores)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	g := NewGame(newTestSettings())
+	g.State.PlayerStates[0].Points = 5
+	if err := g.Timeout(3); err == nil {
+		t.Fatal("unknown player timed out")
+	}
+	if err := g.Timeout(1); err != nil {
+		t.Fatal(err)
+	}
+	if !g.IsGameOver() || g.State.PlayerStates[0].Status != TimedOut || g.State.PlayerStates[0].ThinkingTime != 0 {
+		t.Fatalf("unexpected state: %+v", g.State)
+	}
+	if p, ok := g.GetWinner(); !ok || p.ID != 2 {
+		t.Fatalf("unexpected winner: %v, %v", p, ok)
+	}
+	if err := g.Timeout(2); err == nil {
+		t.Fatal("timed out after game over")
+	}
+
+	g = NewGame(newTestSettings())
+	err := g.ApplyPlayerAction(PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 3}},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 1}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Timeout(1); err != nil {
+		t.Fatal(err)
+	}
+	if len(g.ActionLogs) != 2 || !g.ActionLogs[1].IsTimeout() {
+		t.Fatalf("timeout not logged: %v", g.ActionLogs)
+	}
+	replayed, err := Replay(g.Settings, g.ActionLogs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(g.State, replayed.State) {
+		t.Fatalf("unexpected replay: %+v, want %+v", replayed.State, g.State)
+	}
+	if err := g.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if g.IsGameOver() || len(g.ActionLogs) != 1 || g.State.PlayerStates[0].Points != 2 {
+		t.Fatalf("unexpected state after undo: %+v", g.State)
+	}
+}
+
+func TestScoreFunc(t *testing.T) {
+	settings := newTestSettings()
+	settings.ScoreFunc = func(attacker, defender Action, settings *GameSettings) (int32, int32) {
+		if defender.Type == Defence && defender.Level >= attacker.Level {
+			return 0, 1
+		}
+		return DefaultScore(attacker, defender, settings)
+	}
+	g := NewGame(settings)
+	for _, pas := range []PlayerActionSet{
+		{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 1}},
+			{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 3}},
+		},
+		{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 2}},
+			{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 2}},
+		},
+		{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 3}},
+			{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 1}},
+		},
+	} {
+		if err := g.ApplyPlayerAction(pas); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assertPoints(t, g, 2, 2)
+}
+
+func TestNewGameWithSeed(t *testing.T) {
+	g1 := NewGameWithSeed(newTestSettings(), 42)
+	g2 := NewGameWithSeed(newTestSettings(), 42)
+	if g1.Seed != 42 || g1.Rand.Int63() != g2.Rand.Int63() {
+		t.Fatal("games with the same seed are not deterministic")
+	}
+	if g := NewGame(newTestSettings()); g.Seed != DefaultSeed || g.Rand == nil {
+		t.Fatalf("unexpected seed: %d", g.Seed)
+	}
+}
+
+func TestValidateActions(t *testing.T) {
+	g := NewGame(newTestSettings())
+	if err := g.ValidateActions(PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 1}},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 3}, ThinkingTimeConsumption: time.Hour},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	g.State.PlayerStates[0].Actions = ActionList{{Defence, 1}}
+	for i, pas := range []PlayerActionSet{
+		{{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 1}}},
+		{{PlayerID: 2, TargetPlayerID: 3, Action: Action{Attack, 1}}},
+		{{PlayerID: 3, TargetPlayerID: 1, Action: Action{Attack, 1}}},
+	} {
+		if err := g.ValidateActions(pas); err == nil {
+			t.Errorf("%d: invalid actions accepted", i)
+		}
+	}
+}
+
+func TestActionListCount(t *testing.T) {
+	var empty ActionList
+	if empty.Contains(Action{Attack, 1}) || empty.Count() != 0 || empty.CountByType(Attack) != 0 {
+		t.Fatal("unexpected result for empty list")
+	}
+	al := ActionList{{Attack, 1}, {Attack, 1}, {Defence, 1}}
+	if !al.Contains(Action{Attack, 1}) || al.Contains(Action{Defence, 2}) {
+		t.Fatal("unexpected Contains result")
+	}
+	if al.Count() != 3 || al.CountByType(Attack) != 2 || al.CountByType(Defence) != 1 {
+		t.Fatal("unexpected count")
+	}
+	al, _ = al.Remove(Action{Attack, 1})
+	if !al.Contains(Action{Attack, 1}) || al.CountByType(Attack) != 1 {
+		t.Fatal("repeated action was removed at once")
+	}
+}
+
+func TestForfeit(t *testing.T) {
+	g := NewGame(newTestSettings())
+	err := g.ApplyPlayerAction(PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 3}},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Attack, 1}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Forfeit(3); err == nil {
+		t.Fatal("unknown player forfeited")
+	}
+	if err := g.Forfeit(1); err != nil {
+		t.Fatal(err)
+	}
+	if !g.IsGameOver() || len(g.ActionLogs) != 2 || !g.ActionLogs[1].IsForfeit() || g.ActionLogs[0].IsForfeit() {
+		t.Fatalf("unexpected logs: %+v", g.ActionLogs)
+	}
+	if p, ok := g.GetWinner(); !ok || p.ID != 2 {
+		t.Fatalf("unexpected winner: %v, %v", p, ok)
+	}
+	if err := g.Forfeit(2); err == nil {
+		t.Fatal("forfeited after game over")
+	}
+	replayed, err := Replay(g.Settings, g.ActionLogs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(replayed.State, g.State) {
+		t.Fatalf("unexpected replayed state: %+v", replayed.State)
+	}
+}
+
+func TestGameClone(t *testing.T) {
+	g := NewGame(newTestSettings())
+	err := g.ApplyPlayerAction(PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 3}},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Attack, 1}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := g.Clone()
+	if !reflect.DeepEqual(c.State, g.State) || !reflect.DeepEqual(c.ActionLogs, g.ActionLogs) || c.Settings != g.Settings {
+		t.Fatal("clone differs from the original")
+	}
+	c.ActionLogs[0][0].Action.Level = 2
+	c.State.PlayerStates[0].Points = 0
+	c.State.PlayerStates[0].Actions[0].Level = 9
+	err = c.ApplyPlayerAction(PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 2}},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Attack, 2}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.ActionLogs) != 1 || g.ActionLogs[0][0].Action.Level != 3 ||
+		g.State.PlayerStates[0].Points != 3 || g.State.PlayerStates[0].Actions[0].Level != 1 {
+		t.Fatal("original was mutated")
+	}
+}
+
+func TestFork(t *testing.T) {
+	g := NewGame(newTestSettings())
+	err := g.ApplyPlayerAction(PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 3}},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 1}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, b := g.Fork(), g.Fork()
+	if a.Parent != g || a.Settings == g.Settings || !a.Equal(g) {
+		t.Fatalf("unexpected fork: %+v", a)
+	}
+	if err := a.ApplyPlayerAction(PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 2}},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 2}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.ApplyPlayerAction(PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 2}},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Attack, 1}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	a.Settings.Players[0].Name = "changed"
+	assertPoints(t, a, 2, 3)
+	assertPoints(t, b, 4, 1)
+	assertPoints(t, g, 2, 0)
+	if len(g.ActionLogs) != 1 || len(g.Events) != 1 || g.Settings.Players[0].Name != "P1" {
+		t.Error("parent was mutated")
+	}
+}
+
+func TestPointsLimit(t *testing.T) {
+	settings := newTestSettings()
+	settings.MaxPoints = PointsLimit(4)
+	settings.MinPoints = PointsLimit(0)
+	settings.ScoreFunc = func(attacker, defender Action, settings *GameSettings) (int32, int32) {
+		return int32(attacker.Level) * 2, -int32(attacker.Level)
+	}
+	g := NewGame(settings)
+	err := g.ApplyPlayerAction(PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 3}},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 1}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertPoints(t, g, 4, 0)
+	if g.IsGameOver() {
+		t.Fatal("game over without EndOnMaxPoints")
+	}
+
+	settings.EndOnMaxPoints = true
+	g = NewGame(settings)
+	err = g.ApplyPlayerAction(PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 1}},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Attack, 2}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertPoints(t, g, 0, 4)
+	if !g.IsGameOver() {
+		t.Fatal("game is not over on max points")
+	}
+	if p, ok := g.GetWinner(); !ok || p.ID != 2 {
+		t.Fatalf("unexpected winner: %v, %v", p, ok)
+	}
+
+	settings.MinPoints = PointsLimit(5)
+	if err := settings.Validate(); err == nil {
+		t.Fatal("min points above max points accepted")
+	}
+}
+
+func TestVictoryCondition(t *testing.T) {
+	pas := PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 3}},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Attack, 1}},
+	}
+	for _, tc := range []struct {
+		condition VictoryCondition
+		over      bool
+		winner    PlayerID
+	}{
+		{HighestTotal, false, 1},
+		{FirstToPoints, true, 1},
+		{LowestTotal, false, 2},
+	} {
+		settings := newTestSettings()
+		settings.VictoryCondition = tc.condition
+		settings.VictoryPoints = 3
+		g := NewGame(settings)
+		if err := g.ApplyPlayerAction(pas); err != nil {
+			t.Fatal(err)
+		}
+		if g.IsGameOver() != tc.over {
+			t.Errorf("%d: unexpected game over: %v", tc.condition, g.IsGameOver())
+		}
+		g.State.GameNum = GameOver
+		if p, ok := g.GetWinner(); !ok || p.ID != tc.winner {
+			t.Errorf("%d: unexpected winner: %v, %v", tc.condition, p, ok)
+		}
+		if scores := g.Scores(); scores[0].PlayerID != tc.winner {
+			t.Errorf("%d: unexpected scores: %+v", tc.condition, scores)
+		}
+	}
+
+	settings := newTestSettings()
+	settings.VictoryCondition = FirstToPoints
+	settings.VictoryPoints = 3
+	settings.InitialPoints = map[PlayerID]int32{1: 3}
+	if err := settings.Validate(); err == nil {
+		t.Error("initial points reaching victory points accepted")
+	}
+	if err := NewGame(settings).ApplyPlayerAction(pas); !errors.Is(err, ErrGameOver) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestActionLevelIsValid(t *testing.T) {
+	if !MinActionLevel.IsValid(MinActionLevel, MaxActionLevel) || !MaxActionLevel.IsValid(MinActionLevel, MaxActionLevel) {
+		t.Fatal("boundary levels are invalid")
+	}
+	if ActionLevel(0).IsValid(MinActionLevel, MaxActionLevel) || ActionLevel(-1).IsValid(MinActionLevel, MaxActionLevel) {
+		t.Fatal("non-positive levels are valid")
+	}
+	settings := newTestSettings()
+	settings.Actions = append(settings.Actions, Action{Attack, -3})
+	if err := settings.Validate(); err == nil {
+		t.Fatal("negative level accepted")
+	}
+}
+
+func TestDefaultScoreJustGuard(t *testing.T) {
+	settings := newTestSettings()
+	for _, tc := range []struct {
+		attack, defence    ActionLevel
+		attacker, defender int32
+	}{
+		{3, 2, 1, 0},
+		{2, 2, 0, 3},
+		{1, 2, 0, 0},
+		{MaxActionLevel, MinActionLevel, int32(MaxActionLevel - MinActionLevel), 0},
+		{MinActionLevel, MaxActionLevel, 0, 0},
+		{MaxActionLevel, MaxActionLevel, 0, 3},
+	} {
+		a, d := DefaultScore(Action{Attack, tc.attack}, Action{Defence, tc.defence}, settings)
+		if a != tc.attacker || d != tc.defender {
+			t.Errorf("A%d vs D%d: unexpected points: %d, %d", tc.attack, tc.defence, a, d)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	for _, tc := range []struct {
+		v    fmt.Stringer
+		want string
+	}{
+		{Attack, "Attack"},
+		{Defence, "Defence"},
+		{ActionType(9), "ActionType(9)"},
+		{ActionLevel(2), "L2"},
+		{Action{Defence, 1}, "Defence L1"},
+		{&PlayerAction{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 3}, ThinkingTimeConsumption: 500 * time.Millisecond},
+			"player 1 -> player 2: Attack L3 (consumed 500ms)"},
+		{PlayerAction{PlayerID: 2, TargetPlayerID: 2, Action: Action{Type: Forfeit}}, "player 2 resigned"},
+	} {
+		if s := tc.v.String(); s != tc.want {
+			t.Errorf("unexpected string: %q, want %q", s, tc.want)
+		}
+	}
+}
+
+func TestPreviewOutcome(t *testing.T) {
+	settings := newTestSettings()
+	settings.Players = append(settings.Players, &Player{ID: 3, Name: "P3"})
+	g := NewGame(settings)
+	pas := PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 3, Action: Action{Attack, 2}},
+		{PlayerID: 2, TargetPlayerID: 3, Action: Action{Attack, 3}},
+		{PlayerID: 3, TargetPlayerID: 1, Action: Action{Defence, 2}},
+	}
+	preview, err := g.PreviewOutcome(pas)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(preview, map[PlayerID]int32{1: 0, 2: 1, 3: 3}) || len(g.ActionLogs) != 0 {
+		t.Fatalf("unexpected preview: %v", preview)
+	}
+	before := g.State.Clone()
+	if err := g.ApplyPlayerAction(pas); err != nil {
+		t.Fatal(err)
+	}
+	for _, ps := range g.State.PlayerStates {
+		prev, _ := before.PlayerStates.Get(ps.PlayerID)
+		if ps.Points-prev.Points != preview[ps.PlayerID] {
+			t.Errorf("player %d: preview %d differs from actual %d", ps.PlayerID, preview[ps.PlayerID], ps.Points-prev.Points)
+		}
+	}
+	if _, err := g.PreviewOutcome(pas[:1]); err == nil {
+		t.Fatal("invalid action set previewed")
+	}
+}
+
+func TestLogsByRound(t *testing.T) {
+	settings := newTestSettings()
+	settings.TotalGames = 2
+	settings.Actions = ActionList{{Attack, 1}, {Defence, 1}}
+	g := NewGame(settings)
+	for i := 0; i < 4; i++ {
+		a := settings.Actions[i%2]
+		err := g.ApplyPlayerAction(PlayerActionSet{
+			{PlayerID: 1, TargetPlayerID: 2, Action: a},
+			{PlayerID: 2, TargetPlayerID: 1, Action: a},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !g.IsGameOver() {
+		t.Fatal("game is not over")
+	}
+	logs := g.LogsByRound()
+	if len(logs) != 2 || len(logs[1]) != 2 || len(logs[2]) != 2 || len(logs[GameOver]) != 0 {
+		t.Fatalf("unexpected logs: %v", logs)
+	}
+	if logs[2][1][0] != g.ActionLogs[3][0] {
+		t.Fatal("last action set is not in the last round")
+	}
+}
+
+func TestTimeControlMode(t *testing.T) {
+	for _, tc := range []struct {
+		mode TimeControlMode
+		want time.Duration
+	}{
+		{Fischer, 10*time.Second - 300*time.Millisecond + time.Second},
+		{Bronstein, 10 * time.Second},
+	} {
+		settings := newTestSettings()
+		settings.ThinkingTimeIncrement = time.Second
+		settings.TimeControlMode = tc.mode
+		g := NewGame(settings)
+		err := g.ApplyPlayerAction(PlayerActionSet{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 1}, ThinkingTimeConsumption: 300 * time.Millisecond},
+			{PlayerID: 2, TargetPlayerID: 1, Action: Action{Attack, 1}, ThinkingTimeConsumption: 3 * time.Second},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tt := g.State.PlayerStates[0].ThinkingTime; tt != tc.want {
+			t.Errorf("%d: unexpected thinking time: %v, want %v", tc.mode, tt, tc.want)
+		}
+		if tt := g.State.PlayerStates[1].ThinkingTime; tt != 8*time.Second {
+			t.Errorf("%d: unexpected thinking time: %v", tc.mode, tt)
+		}
+	}
+}
+
+func TestByoYomi(t *testing.T) {
+	settings := newTestSettings()
+	settings.InitialThinkingTime = 3 * time.Second
+	settings.ThinkingTimeIncrement = time.Second
+	settings.ByoYomiPeriods = 3
+	settings.ByoYomiPeriodLength = 10 * time.Second
+	g := NewGame(settings)
+	apply := func(consumption time.Duration) error {
+		return g.ApplyPlayerAction(PlayerActionSet{
+			{PlayerID: 1, TargetPlayerID: 2, Action: g.State.PlayerStates[0].Actions[0], ThinkingTimeConsumption: consumption},
+			{PlayerID: 2, TargetPlayerID: 1, Action: g.State.PlayerStates[1].Actions[0]},
+		})
+	}
+	if err := apply(2 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if ps := g.State.PlayerStates[0]; ps.ThinkingTime != 2*time.Second || ps.ByoYomiPeriods != 3 {
+		t.Fatalf("unexpected clock: %v, %d", ps.ThinkingTime, ps.ByoYomiPeriods)
+	}
+	// Main time runs out but the rest fits in a period.
+	if err := apply(12 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if ps := g.State.PlayerStates[0]; ps.ThinkingTime != 0 || ps.ByoYomiPeriods != 3 {
+		t.Fatalf("unexpected clock: %v, %d", ps.ThinkingTime, ps.ByoYomiPeriods)
+	}
+	// Exceeding a period uses it up.
+	if err := apply(15 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if ps := g.State.PlayerStates[0]; ps.ThinkingTime != 0 || ps.ByoYomiPeriods != 2 {
+		t.Fatalf("unexpected clock: %v, %d", ps.ThinkingTime, ps.ByoYomiPeriods)
+	}
+	if err := apply(25 * time.Second); err == nil {
+		t.Fatal("exceeded all periods")
+	}
+	if err := apply(20 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if ps := g.State.PlayerStates[0]; ps.ByoYomiPeriods != 1 {
+		t.Fatalf("unexpected periods: %d", ps.ByoYomiPeriods)
+	}
+}
+
+func TestMaxThinkingTime(t *testing.T) {
+	settings := newTestSettings()
+	settings.MaxThinkingTime = 12 * time.Second
+	g := NewGame(settings)
+	for i := 0; i < 5; i++ {
+		err := g.ApplyPlayerAction(PlayerActionSet{
+			{PlayerID: 1, TargetPlayerID: 2, Action: g.State.PlayerStates[0].Actions[0], ThinkingTimeConsumption: time.Second},
+			{PlayerID: 2, TargetPlayerID: 1, Action: g.State.PlayerStates[1].Actions[0], ThinkingTimeConsumption: 4 * time.Second},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tt := g.State.PlayerStates[0].ThinkingTime; tt != 12*time.Second {
+			t.Fatalf("%d: clock should plateau: %v", i, tt)
+		}
+	}
+	if tt := g.State.PlayerStates[1].ThinkingTime; tt != 12*time.Second {
+		t.Errorf("unexpected thinking time: %v", tt)
+	}
+	settings.MaxThinkingTime = 5 * time.Second
+	if err := settings.Validate(); err == nil {
+		t.Error("max thinking time less than initial thinking time should be invalid")
+	}
+}
+
+func TestMinThinkingTimeConsumption(t *testing.T) {
+	settings := newTestSettings()
+	settings.MinThinkingTimeConsumption = time.Second
+	g := NewGame(settings)
+	fast := PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 1}, ThinkingTimeConsumption: time.Second},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 1}, ThinkingTimeConsumption: 999 * time.Millisecond},
+	}
+	if err := g.CanApplyPlayerAction(fast); !errors.Is(err, ErrUnderThinkingTime) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := g.ApplyPlayerAction(fast); !errors.Is(err, ErrUnderThinkingTime) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := g.SubmitAction(fast[1]); !errors.Is(err, ErrUnderThinkingTime) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fast[1].ThinkingTimeConsumption = time.Second
+	if err := g.ApplyPlayerAction(fast); err != nil {
+		t.Fatal(err)
+	}
+
+	settings.InitialThinkingTime = InfiniteThinkingTime
+	fast[1].ThinkingTimeConsumption = 0
+	if err := NewGame(settings).ApplyPlayerAction(fast); err != nil {
+		t.Errorf("unlimited clocks have no floor: %v", err)
+	}
+}
+
+func TestRemainingRounds(t *testing.T) {
+	settings := newTestSettings()
+	settings.TotalGames = 5
+	g := NewGame(settings)
+	for _, tc := range []struct {
+		gameNum, want uint32
+	}{
+		{1, 5},
+		{2, 4},
+		{5, 1},
+		{GameOver, 0},
+	} {
+		g.State.GameNum = tc.gameNum
+		if n := g.RemainingRounds(); n != tc.want {
+			t.Errorf("game %d: unexpected remaining rounds: %d, want %d", tc.gameNum, n, tc.want)
+		}
+	}
+}
+
+func TestPlayerActionSetValidate(t *testing.T) {
+	settings := newTestSettings()
+	a := Action{Type: Attack, Level: 1}
+	for _, tc := range []struct {
+		name string
+		pas  PlayerActionSet
+		want string
+	}{
+		{"valid", PlayerActionSet{{PlayerID: 1, TargetPlayerID: 2, Action: a}, {PlayerID: 2, TargetPlayerID: 1, Action: a}}, ""},
+		{"missing", PlayerActionSet{{PlayerID: 1, TargetPlayerID: 2, Action: a}}, "player (id: 2) action is missing"},
+		{"extra", PlayerActionSet{{PlayerID: 1, TargetPlayerID: 2, Action: a}, {PlayerID: 3, TargetPlayerID: 1, Action: a}}, "player (id: 3) is not in the game"},
+		{"duplicate", PlayerActionSet{{PlayerID: 1, TargetPlayerID: 2, Action: a}, {PlayerID: 1, TargetPlayerID: 2, Action: a}}, "player (id: 1) action is duplicated"},
+	} {
+		err := tc.pas.Validate(settings.Players)
+		if tc.want == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", tc.name, err)
+			}
+			continue
+		}
+		if err == nil || err.Error() != tc.want {
+			t.Errorf("%s: unexpected error: %v, want %q", tc.name, err, tc.want)
+		}
+	}
+}
+
+func TestApplyPlayerActionRejectsDuplicatePlayer(t *testing.T) {
+	g := NewGame(newTestSettings())
+	a := Action{Type: Attack, Level: 1}
+	err := g.ApplyPlayerAction(PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 2, Action: a},
+		{PlayerID: 1, TargetPlayerID: 2, Action: a},
+	})
+	if err == nil {
+		t.Fatal("duplicate player action should be rejected")
+	}
+	assertPoints(t, g, 0, 0)
+}
+
+func TestSpectatorView(t *testing.T) {
+	g := NewGame(newTestSettings())
+	id := PlayerID(1)
+	view := g.SpectatorView(&id)
+	if p1, _ := view.PlayerStates.Get(1); len(p1.Actions) != 6 {
+		t.Errorf("own actions should be visible: %v", p1.Actions)
+	}
+	if p2, _ := view.PlayerStates.Get(2); p2.Actions == nil || len(p2.Actions) != 0 {
+		t.Errorf("opponent actions should be redacted: %v", p2.Actions)
+	}
+	if p2, _ := g.State.PlayerStates.Get(2); len(p2.Actions) != 6 {
+		t.Errorf("game state should not be mutated: %v", p2.Actions)
+	}
+	full := g.SpectatorView(nil)
+	if !reflect.DeepEqual(full, g.State) {
+		t.Errorf("unexpected full view: %+v", full)
+	}
+}
+
+func TestCooldownRounds(t *testing.T) {
+	settings := newTestSettings()
+	settings.TotalGames = 5
+	settings.CooldownRounds = 1
+	g := NewGame(settings)
+	apply := func(a1, a2 Action) error {
+		return g.ApplyPlayerAction(PlayerActionSet{
+			{PlayerID: 1, TargetPlayerID: 2, Action: a1},
+			{PlayerID: 2, TargetPlayerID: 1, Action: a2},
+		})
+	}
+	a1, a2, d1, d2 := Action{Attack, 1}, Action{Attack, 2}, Action{Defence, 1}, Action{Defence, 2}
+	if err := apply(a1, d2); err != nil {
+		t.Fatal(err)
+	}
+	if g.State.GameNum != 2 {
+		t.Fatalf("unexpected game num: %d", g.State.GameNum)
+	}
+	p1, _ := g.State.PlayerStates.Get(1)
+	if p1.Actions.Contains(a1) || len(p1.Cooldowns) != 1 {
+		t.Fatalf("used action should be on cooldown: %v", p1)
+	}
+	if err := apply(a1, d1); err == nil {
+		t.Fatal("action on cooldown should be unavailable")
+	}
+	if err := apply(a2, d1); err != nil {
+		t.Fatal(err)
+	}
+	p1, _ = g.State.PlayerStates.Get(1)
+	if !p1.Actions.Contains(a1) || p1.Actions.Contains(a2) || len(p1.Actions) != 5 {
+		t.Fatalf("action should be restored after cooldown: %v", p1.Actions)
+	}
+	if err := apply(a1, d2); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateCooldownRounds(t *testing.T) {
+	settings := newTestSettings()
+	settings.CooldownRounds = uint32(len(settings.Actions))
+	if err := settings.Validate(); err == nil {
+		t.Error("cooldown rounds exhausting the pool should be invalid")
+	}
+}
+
+func TestActionsRemaining(t *testing.T) {
+	g := NewGame(newTestSettings())
+	if n := g.TotalActionsPerRound(); n != 6 {
+		t.Fatalf("unexpected total actions per round: %d", n)
+	}
+	err := g.ApplyPlayerAction(PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 1}},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 1}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings := newTestSettings()
+	settings.ActionsPerRound = 2
+	if n := NewGame(settings).TotalActionsPerRound(); n != 2 {
+		t.Errorf("unexpected total actions per round under ActionsPerRound: %d", n)
+	}
+	if r := g.ActionsRemaining(); !reflect.DeepEqual(r, map[PlayerID]int{1: 5, 2: 5}) {
+		t.Errorf("unexpected actions remaining: %v", r)
+	}
+}
+
+func TestComboBonus(t *testing.T) {
+	settings := newTestSettings()
+	settings.ComboBonus = func(streak int) int32 { return int32(streak - 1) }
+	g := NewGame(settings)
+	for i, want := range []int32{1, 4, 9} {
+		a := Action{Attack, ActionLevel(i + 1)}
+		err := g.ApplyPlayerAction(PlayerActionSet{
+			{PlayerID: 1, TargetPlayerID: 2, Action: a},
+			{PlayerID: 2, TargetPlayerID: 1, Action: a},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertPoints(t, g, want, want)
+	}
+	if ps, _ := g.State.PlayerStates.Get(1); ps.Streak != 3 {
+		t.Fatalf("unexpected streak: %d", ps.Streak)
+	}
+	err := g.ApplyPlayerAction(PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Defence, 1}},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 1}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ps, _ := g.State.PlayerStates.Get(1); ps.Streak != 0 {
+		t.Errorf("defence should reset the streak: %d", ps.Streak)
+	}
+}
+
+func TestInitialPoints(t *testing.T) {
+	settings := newTestSettings()
+	settings.Actions = ActionList{{Attack, 1}}
+	settings.InitialPoints = map[PlayerID]int32{2: 5}
+	g := NewGame(settings)
+	assertPoints(t, g, 0, 5)
+	err := g.ApplyPlayerAction(PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 1}},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Attack, 1}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertPoints(t, g, 1, 6)
+	if p, ok := g.GetWinner(); !ok || p.ID != 2 {
+		t.Errorf("unexpected winner: %v", p)
+	}
+}
+
+func TestValidateInitialPoints(t *testing.T) {
+	settings := newTestSettings()
+	settings.MaxPoints = PointsLimit(5)
+	settings.InitialPoints = map[PlayerID]int32{1: 6, 3: 1}
+	err := settings.Validate()
+	if err == nil {
+		t.Fatal("invalid initial points should be reported")
+	}
+	if n := strings.Count(err.Error(), "\n") + 1; n != 2 {
+		t.Errorf("unexpected number of problems: %d: %v", n, err)
+	}
+}
+
+func TestPlayerActions(t *testing.T) {
+	settings := newTestSettings()
+	settings.TotalGames = 2
+	settings.PlayerActions = map[PlayerID]ActionList{
+		1: {{Attack, 1}, {Attack, 2}},
+		2: {{Defence, 1}},
+	}
+	g := NewGame(settings)
+	if r := g.ActionsRemaining(); !reflect.DeepEqual(r, map[PlayerID]int{1: 2, 2: 1}) {
+		t.Fatalf("unexpected actions remaining: %v", r)
+	}
+	err := g.ApplyPlayerAction(PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 1}},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 1}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.State.GameNum != 2 {
+		t.Fatalf("unexpected game num: %d", g.State.GameNum)
+	}
+	for id, want := range settings.PlayerActions {
+		if ps, _ := g.State.PlayerStates.Get(id); !reflect.DeepEqual(ps.Actions, want) {
+			t.Errorf("player %d: unexpected actions: %v, want %v", id, ps.Actions, want)
+		}
+	}
+	settings.PlayerActions[3] = ActionList{}
+	if err := settings.Validate(); err == nil {
+		t.Error("invalid player actions should be reported")
+	}
+}
+
+func TestGameStateHash(t *testing.T) {
+	g := NewGame(newTestSettings())
+	s := g.State.Clone()
+	if s.Hash() != g.State.Hash() {
+		t.Fatal("clones should hash identically")
+	}
+	s.PlayerStates[0], s.PlayerStates[1] = s.PlayerStates[1], s.PlayerStates[0]
+	s.PlayerStates[0].Actions[0], s.PlayerStates[0].Actions[5] = s.PlayerStates[0].Actions[5], s.PlayerStates[0].Actions[0]
+	if s.Hash() != g.State.Hash() {
+		t.Error("hash should not depend on the order")
+	}
+	s.PlayerStates[0].Points++
+	if s.Hash() == g.State.Hash() {
+		t.Error("point change should alter the hash")
+	}
+}
+
+func TestPass(t *testing.T) {
+	settings := newTestSettings()
+	g := NewGame(settings)
+	pass := Action{Type: Pass}
+	err := g.ApplyPlayerAction(PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 2}},
+		{PlayerID: 2, TargetPlayerID: 1, Action: pass},
+	})
+	if err == nil {
+		t.Fatal("pass should be rejected unless allowed")
+	}
+	settings.AllowPass = true
+	err = g.ApplyPlayerAction(PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 2}},
+		{PlayerID: 2, TargetPlayerID: 1, Action: pass, ThinkingTimeConsumption: time.Second},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertPoints(t, g, 2, 0)
+	p2, _ := g.State.PlayerStates.Get(2)
+	if len(p2.Actions) != 6 || p2.ThinkingTime != 14*time.Second {
+		t.Errorf("pass should only consume thinking time: %+v", p2)
+	}
+	err = g.ApplyPlayerAction(PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 2, Action: pass},
+		{PlayerID: 2, TargetPlayerID: 1, Action: pass},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertPoints(t, g, 2, 0)
+	if r := g.ActionsRemaining(); !reflect.DeepEqual(r, map[PlayerID]int{1: 5, 2: 6}) {
+		t.Errorf("unexpected actions remaining: %v", r)
+	}
+}
+
+func TestGameSettingsClone(t *testing.T) {
+	settings := newTestSettings()
+	settings.MaxPoints = PointsLimit(10)
+	settings.PlayerActions = map[PlayerID]ActionList{1: {{Attack, 1}}}
+	c := settings.Clone()
+	if !reflect.DeepEqual(c, settings) {
+		t.Fatalf("unexpected clone: %+v", c)
+	}
+	c.Actions[0] = Action{Defence, 9}
+	c.Players[0].Name = "X"
+	*c.MaxPoints = 20
+	c.PlayerActions[1][0] = Action{Defence, 9}
+	if settings.Actions[0] != (Action{Attack, 1}) || settings.Players[0].Name != "P1" ||
+		*settings.MaxPoints != 10 || settings.PlayerActions[1][0] != (Action{Attack, 1}) {
+		t.Errorf("original settings were mutated: %+v", settings)
+	}
+}
+
+func TestFormatThinkingTime(t *testing.T) {
+	for _, tc := range []struct {
+		d    time.Duration
+		want string
+	}{
+		{InfiniteThinkingTime, "∞"},
+		{time.Millisecond, "0:00.001"},
+		{65*time.Second + 250*time.Millisecond, "1:05.250"},
+		{75 * time.Minute, "75:00.000"},
+	} {
+		if s := FormatThinkingTime(tc.d); s != tc.want {
+			t.Errorf("%v: unexpected format: %q, want %q", tc.d, s, tc.want)
+		}
+	}
+	if s := (&PlayerState{}).FormatThinkingTime(); s != "0:00.000" {
+		t.Errorf("unexpected format of exhausted clock: %q", s)
+	}
+	settings := newTestSettings()
+	settings.InitialThinkingTime = InfiniteThinkingTime
+	ps := NewGameState(settings).PlayerStates[0]
+	if !ps.Unlimited || ps.FormatThinkingTime() != "∞" {
+		t.Errorf("unexpected unlimited clock: %+v", ps)
+	}
+}
+
+func TestUnlimitedThinkingTime(t *testing.T) {
+	settings := newTestSettings()
+	settings.InitialThinkingTime = InfiniteThinkingTime
+	g := NewGame(settings)
+	err := g.ApplyPlayerAction(PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 1}, ThinkingTimeConsumption: 24 * time.Hour},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Attack, 1}, ThinkingTimeConsumption: time.Duration(math.MaxInt64)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ps := range g.State.PlayerStates {
+		if !ps.Unlimited || ps.ThinkingTime != InfiniteThinkingTime {
+			t.Errorf("unlimited clock should not change: %+v", ps)
+		}
+	}
+
+	g = NewGame(newTestSettings())
+	err = g.ApplyPlayerAction(PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 1}, ThinkingTimeConsumption: 24 * time.Hour},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Attack, 1}},
+	})
+	var te *TimeoutError
+	if !errors.As(err, &te) || te.PlayerID != 1 {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestProgressPercent(t *testing.T) {
+	settings := newTestSettings()
+	settings.TotalGames = 2
+	settings.Actions = ActionList{{Attack, 1}, {Attack, 2}}
+	g := NewGame(settings)
+	for i, want := range []float64{0, 25, 50, 75} {
+		if p := g.ProgressPercent(); p != want {
+			t.Errorf("%d: unexpected progress: %v, want %v", i, p, want)
+		}
+		a := settings.Actions[i%2]
+		err := g.ApplyPlayerAction(PlayerActionSet{
+			{PlayerID: 1, TargetPlayerID: 2, Action: a},
+			{PlayerID: 2, TargetPlayerID: 1, Action: a},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !g.IsGameOver() || g.ProgressPercent() != 100 {
+		t.Errorf("unexpected progress at the end: %v", g.ProgressPercent())
+	}
+}
+
+func TestActionListSorted(t *testing.T) {
+	al := ActionList{{Defence, 2}, {Attack, 3}, {Defence, 1}, {Attack, 1}}
+	sorted := al.Sorted()
+	want := ActionList{{Attack, 1}, {Attack, 3}, {Defence, 1}, {Defence, 2}}
+	if !reflect.DeepEqual(sorted, want) {
+		t.Errorf("unexpected sorted list: %v", sorted)
+	}
+	if al[0] != (Action{Defence, 2}) {
+		t.Errorf("receiver was mutated: %v", al)
+	}
+}
+
+func TestActionListEqual(t *testing.T) {
+	a := ActionList{{Attack, 1}, {Defence, 1}, {Attack, 1}}
+	for _, tc := range []struct {
+		other ActionList
+		want  bool
+	}{
+		{ActionList{{Attack, 1}, {Attack, 1}, {Defence, 1}}, true},
+		{ActionList{{Defence, 1}, {Attack, 1}, {Attack, 1}}, true},
+		{ActionList{{Attack, 1}, {Defence, 1}, {Defence, 1}}, false},
+		{ActionList{{Attack, 1}, {Defence, 1}}, false},
+	} {
+		if eq := a.Equal(tc.other); eq != tc.want {
+			t.Errorf("%v: unexpected equality: %v", tc.other, eq)
+		}
+	}
+}
+
+func TestJustGuardCounter(t *testing.T) {
+	settings := newTestSettings()
+	settings.JustGuardCounter = 2
+	g := NewGame(settings)
+	err := g.ApplyPlayerAction(PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 2}},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 2}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertPoints(t, g, -2, 3)
+	want := []GameEvent{
+		{Type: PointsAwardedEvent, GameNum: 1, PlayerID: 1, TargetPlayerID: 2, Delta: -2},
+		{Type: JustGuardEvent, GameNum: 1, PlayerID: 2, TargetPlayerID: 1, Delta: 3},
+	}
+	if !reflect.DeepEqual(g.Events, want) {
+		t.Errorf("unexpected events: %+v", g.Events)
+	}
+	err = g.ApplyPlayerAction(PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 3}},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 1}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertPoints(t, g, 0, 3)
+
+	settings.JustGuardCounter = -1
+	if err := settings.Validate(); err == nil {
+		t.Error("negative just guard counter should be invalid")
+	}
+}
+
+func TestCanAfford(t *testing.T) {
+	ps := &PlayerState{ThinkingTime: time.Second}
+	if !ps.CanAfford(time.Second) {
+		t.Error("exact consumption should be affordable")
+	}
+	if ps.CanAfford(time.Second + 1) {
+		t.Error("over consumption should not be affordable")
+	}
+	ps = &PlayerState{Unlimited: true}
+	if !ps.CanAfford(24 * time.Hour) {
+		t.Error("unlimited clock should afford anything")
+	}
+}
+
+func TestTimePerRemainingAction(t *testing.T) {
+	for _, tc := range []struct {
+		ps   *PlayerState
+		want time.Duration
+	}{
+		{&PlayerState{ThinkingTime: 12 * time.Second, Actions: ActionList{{Attack, 1}, {Attack, 2}, {Defence, 1}}}, 4 * time.Second},
+		{&PlayerState{ThinkingTime: 10 * time.Second, Actions: ActionList{{Attack, 1}, {Attack, 2}, {Defence, 1}}}, 3333333333},
+		{&PlayerState{ThinkingTime: 12 * time.Second, Actions: ActionList{{Attack, 1}, {Attack, 2}}, UsedActions: ActionList{{Attack, 1}}}, 12 * time.Second},
+		{&PlayerState{ThinkingTime: 5 * time.Second}, 5 * time.Second},
+		{&PlayerState{Unlimited: true, Actions: ActionList{{Attack, 1}}}, InfiniteThinkingTime},
+	} {
+		if got := tc.ps.TimePerRemainingAction(); got != tc.want {
+			t.Errorf("%+v: got %v, want %v", tc.ps, got, tc.want)
+		}
+	}
+
+	settings := newTestSettings()
+	settings.Actions = ActionList{{Attack, 1}, {Attack, 2}, {Attack, 3}, {Defence, 1}, {Defence, 2}}
+	settings.ActionsPerRound = 3
+	g := NewGame(settings)
+	if d, err := g.TimePerRemainingAction(1); err != nil || d != 10*time.Second/3 {
+		t.Errorf("unexpected time per action: %v, %v", d, err)
+	}
+	if d := g.State.PlayerStates[0].TimePerRemainingAction(); d != 2*time.Second {
+		t.Errorf("unexpected time per action: %v", d)
+	}
+	if _, err := g.TimePerRemainingAction(3); err == nil {
+		t.Error("unknown player accepted")
+	}
+}
+
+func TestAttackClashMode(t *testing.T) {
+	for _, tc := range []struct {
+		mode AttackClashMode
+		want []int32
+	}{
+		{BothAttacksScore, []int32{3, 1}},
+		{HigherAttackWins, []int32{2, 0}},
+	} {
+		settings := newTestSettings()
+		settings.AttackClashMode = tc.mode
+		g := NewGame(settings)
+		err := g.ApplyPlayerAction(PlayerActionSet{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 3}},
+			{PlayerID: 2, TargetPlayerID: 1, Action: Action{Attack, 1}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertPoints(t, g, tc.want...)
+	}
+	settings := newTestSettings()
+	settings.AttackClashMode = HigherAttackWins
+	g := NewGame(settings)
+	err := g.ApplyPlayerAction(PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 2}},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Attack, 2}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertPoints(t, g, 0, 0)
+}
+
+func TestCounter(t *testing.T) {
+	for _, tc := range []struct {
+		attack, counter ActionLevel
+		margin          int32
+		want            []int32
+	}{
+		{1, 3, 0, []int32{3, 0}},
+		{1, 3, 2, []int32{3, 0}},
+		{1, 3, 3, []int32{5, 0}},
+		{2, 2, 0, []int32{5, 3}},
+		{3, 1, 0, []int32{7, 0}},
+	} {
+		settings := newTestSettings()
+		settings.Actions = append(settings.Actions, Action{Counter, 1}, Action{Counter, 2}, Action{Counter, 3})
+		settings.InitialPoints = map[PlayerID]int32{1: 5}
+		settings.CounterMargin = tc.margin
+		g := NewGame(settings)
+		err := g.ApplyPlayerAction(PlayerActionSet{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, tc.attack}},
+			{PlayerID: 2, TargetPlayerID: 1, Action: Action{Counter, tc.counter}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertPoints(t, g, tc.want...)
+	}
+
+	settings := newTestSettings()
+	settings.Actions = append(settings.Actions, Action{Counter, 3})
+	g := NewGame(settings)
+	err := g.ApplyPlayerAction(PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Defence, 1}},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Counter, 3}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertPoints(t, g, 0, 0)
+
+	settings.CounterMargin = -1
+	if err := settings.Validate(); err == nil {
+		t.Error("negative counter margin accepted")
+	}
+}
+
+func TestActionsPerRound(t *testing.T) {
+	settings := newTestSettings()
+	settings.TotalGames = 2
+	settings.Actions = ActionList{{Attack, 1}, {Attack, 2}, {Attack, 3}, {Defence, 1}, {Defence, 2}}
+	settings.ActionsPerRound = 3
+	g := NewGame(settings)
+	for i, defence := range []Action{{Defence, 1}, {Defence, 2}, {Attack, 1}} {
+		if g.State.GameNum != 1 {
+			t.Fatalf("round advanced after %d moves", i)
+		}
+		err := g.ApplyPlayerAction(PlayerActionSet{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, ActionLevel(i + 1)}},
+			{PlayerID: 2, TargetPlayerID: 1, Action: defence},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i == 1 && g.ActionsRemaining()[1] != 1 {
+			t.Errorf("unexpected actions remaining: %v", g.ActionsRemaining())
+		}
+	}
+	if g.State.GameNum != 2 {
+		t.Fatalf("round not advanced: %d", g.State.GameNum)
+	}
+	ps, _ := g.State.PlayerStates.Get(1)
+	if len(ps.Actions) != 5 || g.ActionsRemaining()[1] != 3 {
+		t.Errorf("pool not refreshed: %v", ps.Actions)
+	}
+
+	settings.RoundAdvancePolicy = AllPlayersEmpty
+	settings.PlayerActions = map[PlayerID]ActionList{2: {{Defence, 1}, {Defence, 2}}}
+	g = NewGame(settings)
+	for i := 0; i < 2; i++ {
+		err := g.ApplyPlayerAction(PlayerActionSet{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Defence, ActionLevel(i + 1)}},
+			{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, ActionLevel(i + 1)}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if acting := g.actingPlayers(g.State); g.State.GameNum != 1 || len(acting) != 1 || acting[0].ID != 1 {
+		t.Fatalf("unexpected acting players: %v", acting)
+	}
+	err := g.ApplyPlayerAction(PlayerActionSet{{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 1}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.State.GameNum != 2 {
+		t.Fatalf("round not advanced: %d", g.State.GameNum)
+	}
+
+	settings.ActionsPerRound = -1
+	if err := settings.Validate(); err == nil {
+		t.Error("negative actions per round accepted")
+	}
+}
+
+func TestBlockedAttackPenalty(t *testing.T) {
+	settings := newTestSettings()
+	settings.BlockedAttackPenalty = 1
+	settings.InitialPoints = map[PlayerID]int32{1: 5}
+	g := NewGame(settings)
+	err := g.ApplyPlayerAction(PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 2}},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 3}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertPoints(t, g, 3, 0)
+
+	settings.MinPoints = PointsLimit(0)
+	settings.InitialPoints = nil
+	g = NewGame(settings)
+	err = g.ApplyPlayerAction(PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 2}},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 3}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertPoints(t, g, 0, 0)
+	if len(g.Events) != 0 {
+		t.Errorf("clamped penalty should not be an event: %+v", g.Events)
+	}
+}
+
+func TestLastAction(t *testing.T) {
+	g := NewGame(newTestSettings())
+	if _, ok := g.LastAction(); ok {
+		t.Fatal("no action should be found")
+	}
+	if _, ok := g.LastActionFor(1); ok {
+		t.Fatal("no action should be found")
+	}
+	for _, level := range []ActionLevel{1, 2} {
+		err := g.ApplyPlayerAction(PlayerActionSet{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, level}},
+			{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, level}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if pas, ok := g.LastAction(); !ok || len(pas) != 2 || pas[0].Action.Level != 2 {
+		t.Errorf("unexpected last action: %v", pas)
+	}
+	if pa, ok := g.LastActionFor(2); !ok || pa.Action != (Action{Defence, 2}) {
+		t.Errorf("unexpected last action: %v", pa)
+	}
+	if _, ok := g.LastActionFor(3); ok {
+		t.Error("unknown player should not have the last action")
+	}
+}
+
+func TestRoundAdvancePolicy(t *testing.T) {
+	newSettings := func(policy RoundAdvancePolicy) *GameSettings {
+		settings := newTestSettings()
+		settings.TotalGames = 2
+		settings.RoundAdvancePolicy = policy
+		settings.PlayerActions = map[PlayerID]ActionList{
+			1: {{Attack, 1}, {Attack, 2}},
+			2: {{Defence, 1}},
+		}
+		return settings
+	}
+	first := PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 2}},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 1}},
+	}
+
+	g := NewGame(newSettings(AnyPlayerEmpty))
+	if err := g.ApplyPlayerAction(first); err != nil {
+		t.Fatal(err)
+	}
+	if g.State.GameNum != 2 {
+		t.Errorf("round should advance when any player is empty: %d", g.State.GameNum)
+	}
+
+	g = NewGame(newSettings(AllPlayersEmpty))
+	if err := g.ApplyPlayerAction(first); err != nil {
+		t.Fatal(err)
+	}
+	if g.State.GameNum != 1 || !reflect.DeepEqual(g.PendingPlayers(), []PlayerID{1}) {
+		t.Fatalf("round should not advance yet: %d, %v", g.State.GameNum, g.PendingPlayers())
+	}
+	err := g.ApplyPlayerAction(PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 1}},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 1}},
+	})
+	if err == nil {
+		t.Fatal("waiting player should not submit")
+	}
+	if err := PlayOut(g.Clone(), map[PlayerID]Agent{1: &RandomAgent{}, 2: &RandomAgent{}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.SubmitAction(&PlayerAction{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 1}}); err != nil {
+		t.Fatal(err)
+	}
+	if g.State.GameNum != 2 {
+		t.Errorf("round should advance when all players are empty: %d", g.State.GameNum)
+	}
+	// The waiting player cannot defend.
+	assertPoints(t, g, 2, 0)
+}
+
+func TestResetPointsEachRound(t *testing.T) {
+	settings := newTestSettings()
+	settings.TotalGames = 3
+	settings.ResetPointsEachRound = true
+	settings.PlayerActions = map[PlayerID]ActionList{
+		1: {{Attack, 2}, {Defence, 1}},
+		2: {{Attack, 1}, {Defence, 2}},
+	}
+	g := NewGame(settings)
+	apply := func(a1, a2 Action) {
+		t.Helper()
+		err := g.ApplyPlayerAction(PlayerActionSet{
+			{PlayerID: 1, TargetPlayerID: 2, Action: a1},
+			{PlayerID: 2, TargetPlayerID: 1, Action: a2},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Player 1 wins the first round by 2 to 1.
+	apply(Action{Attack, 2}, Action{Attack, 1})
+	assertPoints(t, g, 2, 1)
+	apply(Action{Defence, 1}, Action{Defence, 2})
+	assertPoints(t, g, 0, 0)
+	if !reflect.DeepEqual(g.State.RoundWins, map[PlayerID]int{1: 1}) {
+		t.Fatalf("unexpected round wins: %v", g.State.RoundWins)
+	}
+	// The second round is a draw by just guards.
+	apply(Action{Attack, 2}, Action{Defence, 2})
+	apply(Action{Defence, 1}, Action{Attack, 1})
+	if !reflect.DeepEqual(g.State.RoundWins, map[PlayerID]int{1: 1}) {
+		t.Fatalf("unexpected round wins: %v", g.State.RoundWins)
+	}
+	apply(Action{Attack, 2}, Action{Attack, 1})
+	apply(Action{Defence, 1}, Action{Defence, 2})
+	if !g.IsGameOver() || !reflect.DeepEqual(g.State.RoundWins, map[PlayerID]int{1: 2}) {
+		t.Fatalf("unexpected round wins: %v", g.State.RoundWins)
+	}
+	g.State.PlayerStates[1].Points = 100
+	if p, ok := g.GetWinner(); !ok || p.ID != 1 {
+		t.Errorf("winner should be decided by round wins: %v", p)
+	}
+}
+
+func TestValidateState(t *testing.T) {
+	if err := NewGame(newTestSettings()).ValidateState(); err != nil {
+		t.Fatal(err)
+	}
+	for i, corrupt := range []func(g *Game){
+		func(g *Game) { g.State = nil },
+		func(g *Game) { g.State.GameNum = 2 },
+		func(g *Game) { g.State.PlayerStates = g.State.PlayerStates[:1] },
+		func(g *Game) { g.State.PlayerStates[1].PlayerID = 1 },
+		func(g *Game) { g.State.PlayerStates[0].ThinkingTime = -time.Second },
+		func(g *Game) { g.State.PlayerStates[0].Status = 9 },
+		func(g *Game) { g.State.PlayerStates[0].ByoYomiPeriods = 1 },
+		func(g *Game) { g.State.PlayerStates[0].Actions[0] = Action{Attack, 9} },
+		func(g *Game) {
+			g.State.PlayerStates[0].Actions = append(g.State.PlayerStates[0].Actions, Action{Attack, 1})
+		},
+	} {
+		g := NewGame(newTestSettings())
+		corrupt(g)
+		if err := g.ValidateState(); err == nil {
+			t.Errorf("%d: corrupted state should be invalid", i)
+		}
+	}
+}
+
+func TestSummary(t *testing.T) {
+	settings := newTestSettings()
+	settings.TotalGames = 2
+	settings.Actions = ActionList{{Attack, 2}, {Defence, 1}}
+	g := NewGame(settings)
+	if _, err := g.Summary(); err == nil {
+		t.Fatal("summarized an ongoing game")
+	}
+	sets := []PlayerActionSet{
+		{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 2}, ThinkingTimeConsumption: 3 * time.Second},
+			{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 1}, ThinkingTimeConsumption: 9 * time.Second},
+		},
+		{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Defence, 1}},
+			{PlayerID: 2, TargetPlayerID: 1, Action: Action{Attack, 2}},
+		},
+	}
+	if _, err := g.ApplyPlayerActions(sets); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Timeout(2); err != nil {
+		t.Fatal(err)
+	}
+	r, err := g.Summary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &GameResult{
+		Winner: &Player{ID: 1, Name: "P1"},
+		Scores: []PlayerScore{
+			{PlayerID: 1, Name: "P1", Points: 1, ThinkingTime: 17 * time.Second},
+			{PlayerID: 2, Name: "P2", Points: 1, ThinkingTime: 0},
+		},
+		Rounds:       2,
+		TotalActions: 4,
+	}
+	if !reflect.DeepEqual(r, want) {
+		t.Errorf("unexpected result: %+v, want %+v", r, want)
+	}
+
+	g = NewGame(settings)
+	if _, err := g.ApplyPlayerActions(sets[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Forfeit(2); err != nil {
+		t.Fatal(err)
+	}
+	if r, err := g.Summary(); err != nil || r.TotalActions != 2 {
+		t.Errorf("unexpected result: %+v, %v", r, err)
+	}
+}
+
+func TestTieBreakers(t *testing.T) {
+	settings := newTestSettings()
+	settings.RoundAdvancePolicy = AllPlayersEmpty
+	settings.PlayerActions = map[PlayerID]ActionList{
+		1: {{Attack, 1}},
+		2: {{Attack, 1}, {Defence, 1}},
+	}
+	g := NewGame(settings)
+	sets := []PlayerActionSet{
+		{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 1}, ThinkingTimeConsumption: time.Second},
+			{PlayerID: 2, TargetPlayerID: 1, Action: Action{Attack, 1}},
+		},
+		{
+			{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 1}},
+		},
+	}
+	if _, err := g.ApplyPlayerActions(sets); err != nil {
+		t.Fatal(err)
+	}
+	assertPoints(t, g, 1, 1)
+	if !g.IsDraw() {
+		t.Fatal("points should be tied without tie breakers")
+	}
+	for _, tc := range []struct {
+		tieBreakers []TieBreaker
+		winner      PlayerID
+	}{
+		{[]TieBreaker{MoreThinkingTime}, 2},
+		{[]TieBreaker{FewerActionsUsed, MoreThinkingTime}, 1},
+		{[]TieBreaker{LowerPlayerID}, 1},
+	} {
+		g.Settings.TieBreakers = tc.tieBreakers
+		if p, ok := g.GetWinner(); !ok || p.ID != tc.winner || g.IsDraw() {
+			t.Errorf("%v: unexpected winner: %v, %v", tc.tieBreakers, p, ok)
+		}
+		if s := g.Scores(); s[0].PlayerID != tc.winner {
+			t.Errorf("%v: unexpected scores: %+v", tc.tieBreakers, s)
+		}
+	}
+	g.Settings.TieBreakers = []TieBreaker{3}
+	if err := g.Settings.Validate(); err == nil {
+		t.Error("unknown tie breaker should be invalid")
+	}
+}
+
+func TestTrackUsedActions(t *testing.T) {
+	settings := newTestSettings()
+	settings.TotalGames = 2
+	settings.TrackUsedActions = true
+	g := NewGame(settings)
+	sets := []PlayerActionSet{
+		{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 1}},
+			{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 3}},
+		},
+		{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 3}},
+			{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 1}},
+		},
+	}
+	if _, err := g.ApplyPlayerActions(sets); err != nil {
+		t.Fatal(err)
+	}
+	ps := g.State.PlayerStates[0]
+	if len(ps.Actions) != 6 || !ps.UsedActions.Equal(ActionList{{Attack, 1}, {Attack, 3}}) {
+		t.Fatalf("unexpected actions: %v, %v", ps.Actions, ps.UsedActions)
+	}
+	if !ps.AvailableActions().Equal(ActionList{{Attack, 2}, {Defence, 1}, {Defence, 2}, {Defence, 3}}) {
+		t.Errorf("unexpected available actions: %v", ps.AvailableActions())
+	}
+	if r := g.ActionsRemaining(); r[1] != 4 || r[2] != 4 {
+		t.Errorf("unexpected remaining actions: %v", r)
+	}
+	if n := len(g.LegalActions(1)); n != 4 {
+		t.Errorf("unexpected number of legal actions: %d", n)
+	}
+	if err := g.ApplyPlayerAction(sets[0]); !errors.Is(err, ErrActionUnavailable) {
+		t.Fatalf("used action accepted: %v", err)
+	}
+	if err := g.ValidateState(); err != nil {
+		t.Fatal(err)
+	}
+	for _, a := range ps.AvailableActions() {
+		err := g.ApplyPlayerAction(PlayerActionSet{
+			{PlayerID: 1, TargetPlayerID: 2, Action: a},
+			{PlayerID: 2, TargetPlayerID: 1, Action: g.State.PlayerStates[1].AvailableActions()[0]},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if ps := g.State.PlayerStates[0]; g.State.GameNum != 2 || len(ps.Actions) != 6 || len(ps.UsedActions) != 0 {
+		t.Errorf("used actions should be reset on round advance: %d, %v", g.State.GameNum, ps.UsedActions)
+	}
+	settings.CooldownRounds = 1
+	if err := settings.Validate(); err == nil {
+		t.Error("track used actions with cooldown rounds should be invalid")
+	}
+}
+
+func TestPointsHistory(t *testing.T) {
+	settings := newTestSettings()
+	settings.TotalGames = 3
+	settings.Actions = ActionList{{Attack, 2}, {Defence, 1}}
+	g := NewGame(settings)
+	guarded := []PlayerActionSet{
+		{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 2}},
+			{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 1}},
+		},
+		{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Defence, 1}},
+			{PlayerID: 2, TargetPlayerID: 1, Action: Action{Attack, 2}},
+		},
+	}
+	clashed := []PlayerActionSet{
+		{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 2}},
+			{PlayerID: 2, TargetPlayerID: 1, Action: Action{Attack, 2}},
+		},
+		{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Defence, 1}},
+			{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 1}},
+		},
+	}
+	for _, sets := range [][]PlayerActionSet{guarded, clashed, guarded} {
+		if _, err := g.ApplyPlayerActions(sets); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !g.IsGameOver() {
+		t.Fatal("game should be over")
+	}
+	for _, ps := range g.State.PlayerStates {
+		if !reflect.DeepEqual(ps.PointsHistory, []int32{0, 1, 3}) || ps.Points != 4 {
+			t.Errorf("unexpected history: %v, %d", ps.PointsHistory, ps.Points)
+		}
+	}
+}
+
+func TestSwap(t *testing.T) {
+	settings := newTestSettings()
+	settings.Actions = ActionList{{Attack, 3}, {Swap, 1}}
+	settings.InitialPoints = map[PlayerID]int32{2: 5}
+	g := NewGame(settings)
+	err := g.ApplyPlayerAction(PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Swap, 1}},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Attack, 3}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The attack is scored first and then the points are swapped.
+	assertPoints(t, g, 8, 0)
+	if len(g.State.PlayerStates[0].Actions) != 1 {
+		t.Errorf("swap should be consumed: %v", g.State.PlayerStates[0].Actions)
+	}
+
+	g = NewGame(settings)
+	err = g.ApplyPlayerAction(PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Swap, 1}},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Swap, 1}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertPoints(t, g, 0, 5)
+
+	pas := PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 1, Action: Action{Swap, 1}},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Attack, 3}},
+	}
+	if err := NewGame(settings).ApplyPlayerAction(pas); !errors.Is(err, ErrSelfTarget) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if a, err := ParseActionType("swap"); err != nil || a != Swap {
+		t.Errorf("unexpected action type: %v, %v", a, err)
+	}
+}
+
+func TestRoundWinners(t *testing.T) {
+	settings := newTestSettings()
+	settings.TotalGames = 3
+	settings.Actions = ActionList{{Attack, 1}, {Attack, 3}, {Defence, 1}}
+	g := NewGame(settings)
+	rounds := [][2][]Action{
+		{{{Attack, 3}, {Attack, 1}, {Defence, 1}}, {{Defence, 1}, {Attack, 3}, {Attack, 1}}},
+		{{{Defence, 1}, {Attack, 3}, {Attack, 1}}, {{Attack, 3}, {Attack, 1}, {Defence, 1}}},
+		{{{Attack, 3}, {Attack, 1}, {Defence, 1}}, {{Attack, 3}, {Attack, 1}, {Defence, 1}}},
+	}
+	for n, round := range rounds {
+		if len(g.RoundWinners()) > n {
+			t.Fatalf("unfinished round reported: %v", g.RoundWinners())
+		}
+		for i := range round[0] {
+			err := g.ApplyPlayerAction(PlayerActionSet{
+				{PlayerID: 1, TargetPlayerID: 2, Action: round[0][i]},
+				{PlayerID: 2, TargetPlayerID: 1, Action: round[1][i]},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if !g.IsGameOver() {
+		t.Fatal("game not over")
+	}
+	want := map[uint32]PlayerID{1: 1, 2: 2}
+	if got := g.RoundWinners(); !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected round winners: %v, want %v", got, want)
+	}
+
+	g = NewGame(settings)
+	err := g.ApplyPlayerAction(PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 3}},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 1}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Forfeit(1); err != nil {
+		t.Fatal(err)
+	}
+	if got := g.RoundWinners(); len(got) != 0 {
+		t.Errorf("forfeited round reported: %v", got)
+	}
+
+	settings.Players = append(settings.Players, &Player{ID: 3, Name: "P3"})
+	g = NewGame(settings)
+	for _, round := range rounds[:1] {
+		for i := range round[0] {
+			err := g.ApplyPlayerAction(PlayerActionSet{
+				{PlayerID: 1, TargetPlayerID: 2, Action: round[0][i]},
+				{PlayerID: 2, TargetPlayerID: 1, Action: round[1][i]},
+				{PlayerID: 3, TargetPlayerID: 1, Action: round[0][i]},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if got := g.RoundWinners(); !reflect.DeepEqual(got, map[uint32]PlayerID{1: 1}) {
+		t.Errorf("unexpected round winners: %v", got)
+	}
+	// Leaving later does not change the winner of a finished round.
+	if err := g.Eliminate(1); err != nil {
+		t.Fatal(err)
+	}
+	if got := g.RoundWinners(); !reflect.DeepEqual(got, map[uint32]PlayerID{1: 1}) {
+		t.Errorf("unexpected round winners: %v", got)
+	}
+
+	settings.Players = settings.Players[:2]
+	g = NewGame(settings)
+	for i := range rounds[0][0] {
+		err := g.ApplyPlayerAction(PlayerActionSet{
+			{PlayerID: 1, TargetPlayerID: 2, Action: rounds[0][0][i]},
+			{PlayerID: 2, TargetPlayerID: 1, Action: rounds[0][1][i]},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := g.Forfeit(1); err != nil {
+		t.Fatal(err)
+	}
+	if got := g.RoundWinners(); !reflect.DeepEqual(got, map[uint32]PlayerID{1: 1}) {
+		t.Errorf("forfeit should not change finished rounds: %v", got)
+	}
+}
+
+func TestThinkingTimeUsed(t *testing.T) {
+	g := NewGame(newTestSettings())
+	sets := []PlayerActionSet{
+		{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 1}, ThinkingTimeConsumption: 1500 * time.Millisecond},
+			{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 1}, ThinkingTimeConsumption: 8 * time.Second},
+		},
+		{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 2}, ThinkingTimeConsumption: 2 * time.Second},
+			{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 2}, ThinkingTimeConsumption: 6 * time.Second},
+		},
+	}
+	if _, err := g.ApplyPlayerActions(sets); err != nil {
+		t.Fatal(err)
+	}
+	want := map[PlayerID]time.Duration{1: 3500 * time.Millisecond, 2: 14 * time.Second}
+	if r := g.ThinkingTimeUsed(); !reflect.DeepEqual(r, want) {
+		t.Errorf("unexpected thinking time used: %v", r)
+	}
+}
+
+func TestMaxRounds(t *testing.T) {
+	settings := newTestSettings()
+	settings.AllowPass = true
+	settings.MaxRounds = 10
+	g := NewGame(settings)
+	stall := PlayerActionSet{
+		{PlayerID: 1, Action: Action{Type: Pass}},
+		{PlayerID: 2, Action: Action{Type: Pass}},
+	}
+	for i := 0; !g.IsGameOver(); i++ {
+		if i >= 10 {
+			t.Fatal("stalled game did not terminate")
+		}
+		if err := g.ApplyPlayerAction(stall); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(g.ActionLogs) != 10 || g.State.Turns != 10 || len(g.State.PlayerStates[0].Actions) != 6 {
+		t.Errorf("unexpected game: %d, %d", len(g.ActionLogs), g.State.Turns)
+	}
+
+	settings = newTestSettings()
+	settings.MaxRounds = 7
+	g = NewGame(settings)
+	if err := PlayOut(g, map[PlayerID]Agent{1: &RandomAgent{}, 2: &RandomAgent{}}); err != nil {
+		t.Fatal(err)
+	}
+	if len(g.ActionLogs) != len(settings.Actions) {
+		t.Errorf("larger max rounds should not interfere: %d", len(g.ActionLogs))
+	}
+}
+
+func TestEliminated(t *testing.T) {
+	settings := newTestSettings()
+	settings.Players = append(settings.Players, &Player{ID: 3, Name: "P3"})
+	settings.MinPoints = PointsLimit(0)
+	settings.InitialPoints = map[PlayerID]int32{1: 3, 2: 3, 3: 3}
+	settings.EliminateAtMinPoints = true
+	// An undefended attack takes the points from the target.
+	settings.ScoreFunc = func(attacker, defender Action, settings *GameSettings) (int32, int32) {
+		if defender.Type == Defence && defender.Level >= attacker.Level {
+			return 0, 0
+		}
+		return int32(attacker.Level), -int32(attacker.Level)
+	}
+	g := NewGame(settings)
+	err := g.ApplyPlayerAction(PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 3, Action: Action{Attack, 3}},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 1}},
+		{PlayerID: 3, TargetPlayerID: 1, Action: Action{Defence, 1}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ps, _ := g.State.PlayerStates.Get(3); ps.Status != Eliminated || g.IsGameOver() {
+		t.Fatalf("unexpected state: %+v", ps)
+	}
+	if a := g.State.ActivePlayers(); !reflect.DeepEqual(a, []PlayerID{1, 2}) {
+		t.Fatalf("unexpected active players: %v", a)
+	}
+	if err := g.ApplyPlayerAction(PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 3, Action: Action{Attack, 1}},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 2}},
+	}); !errors.Is(err, ErrPlayerEliminated) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, pa := range g.LegalActions(1) {
+		if pa.TargetPlayerID == 3 {
+			t.Fatalf("eliminated player is targeted: %v", pa)
+		}
+	}
+	if r := g.LegalActions(3); r != nil {
+		t.Fatalf("eliminated player should not act: %v", r)
+	}
+	if err := PlayOut(g, map[PlayerID]Agent{1: &RandomAgent{}, 2: &RandomAgent{}}); err != nil {
+		t.Fatal(err)
+	}
+	if p, ok := g.GetWinner(); ok && p.ID == 3 {
+		t.Error("eliminated player won")
+	}
+
+	g = NewGame(settings)
+	if err := g.Eliminate(2); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Eliminate(2); !errors.Is(err, ErrPlayerEliminated) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := g.Eliminate(3); err != nil {
+		t.Fatal(err)
+	}
+	if p, ok := g.GetWinner(); !ok || p.ID != 1 {
+		t.Errorf("the last player should win: %v, %v", p, ok)
+	}
+}
+
+func TestEliminatedCannotEndGame(t *testing.T) {
+	settings := newTestSettings()
+	settings.Players = append(settings.Players, &Player{ID: 3, Name: "P3"})
+	g := NewGame(settings)
+	if err := g.Eliminate(3); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Forfeit(3); !errors.Is(err, ErrPlayerEliminated) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := g.Timeout(3); !errors.Is(err, ErrPlayerEliminated) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if g.IsGameOver() || len(g.ActionLogs) != 1 {
+		t.Fatalf("eliminated player ended the game: %v", g.ActionLogs)
+	}
+	if ps, _ := g.State.PlayerStates.Get(3); ps.Status != Eliminated {
+		t.Errorf("unexpected status: %v", ps.Status)
+	}
+}
+
+func TestEliminateReplay(t *testing.T) {
+	settings := newTestSettings()
+	settings.Players = append(settings.Players, &Player{ID: 3, Name: "P3"})
+	g := NewGame(settings)
+	if err := g.Eliminate(3); err != nil {
+		t.Fatal(err)
+	}
+	if len(g.ActionLogs) != 1 || !g.ActionLogs[0].IsElimination() {
+		t.Fatalf("elimination not logged: %v", g.ActionLogs)
+	}
+	for i := ActionLevel(1); i <= 2; i++ {
+		err := g.ApplyPlayerAction(PlayerActionSet{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, i}},
+			{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 3 - i}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	replayed, err := Replay(settings, g.ActionLogs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(g.State, replayed.State) || !reflect.DeepEqual(g.Events, replayed.Events) {
+		t.Fatalf("unexpected replay: %+v, want %+v", replayed.State, g.State)
+	}
+	if _, err := g.Timeline(); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.WriteCSV(io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(g.LogsByRound()[1]); n != 3 {
+		t.Errorf("unexpected logs in round 1: %d", n)
+	}
+	if err := g.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if ps, _ := g.State.PlayerStates.Get(3); ps.Status != Eliminated || len(g.ActionLogs) != 1 {
+		t.Fatalf("unexpected state after undo: %+v", g.State)
+	}
+	if err := g.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if ps, _ := g.State.PlayerStates.Get(3); ps.Status != Playing {
+		t.Fatalf("elimination not undone: %+v", ps)
+	}
+}
@@ -0,0 +1,50 @@
+package core
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+var csvHeader = []string{
+	"round", "playerId", "targetPlayerId", "actionType", "actionLevel", "thinkingTimeMs", "resultingPoints",
+}
+
+// WriteCSV writes one row per PlayerAction of ActionLogs to w with a header.
+// resultingPoints is the points of the player after its action set was
+// applied, derived by replaying ActionLogs.
+func (g *Game) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	state := NewGameState(g.Settings)
+	for i, pas := range g.ActionLogs {
+		next, _, err := g.nextState(state, pas)
+		if err != nil {
+			return fmt.Errorf("action log %d: %w", i, err)
+		}
+		for _, pa := range pas {
+			ps, found := next.PlayerStates.Get(pa.PlayerID)
+			if !found {
+				return &PlayerNotFoundError{PlayerID: pa.PlayerID}
+			}
+			err := cw.Write([]string{
+				strconv.FormatUint(uint64(state.GameNum), 10),
+				strconv.FormatUint(uint64(pa.PlayerID), 10),
+				strconv.FormatUint(uint64(pa.TargetPlayerID), 10),
+				pa.Action.Type.String(),
+				strconv.Itoa(int(pa.Action.Level)),
+				strconv.FormatInt(pa.ThinkingTimeConsumption.Milliseconds(), 10),
+				strconv.FormatInt(int64(ps.Points), 10),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		state = next
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,45 @@
+package core
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWriteCSV(t *testing.T) {
+	g := NewGame(newTestSettings())
+	for _, pas := range []PlayerActionSet{
+		{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 3}, ThinkingTimeConsumption: time.Second},
+			{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 1}, ThinkingTimeConsumption: 2 * time.Second},
+		},
+		{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 2}, ThinkingTimeConsumption: 500 * time.Millisecond},
+			{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 2}},
+		},
+	} {
+		if err := g.ApplyPlayerAction(pas); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var buf bytes.Buffer
+	if err := g.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1+4 {
+		t.Fatalf("unexpected number of records: %d", len(records))
+	}
+	if !reflect.DeepEqual(records[0], csvHeader) {
+		t.Errorf("unexpected header: %v", records[0])
+	}
+	want := []string{"1", "2", "1", "Defence", "2", "0", "3"}
+	if !reflect.DeepEqual(records[4], want) {
+		t.Errorf("unexpected record: %v, want %v", records[4], want)
+	}
+}
@@ -0,0 +1,106 @@
+package core
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Equal returns true if Diff reports no difference.
+func (g *Game) Equal(other *Game) bool {
+	return len(g.Diff(other)) == 0
+}
+
+// Diff describes the differences of the states and the logs of g and other.
+// It compares what GameState.Hash covers, PointsHistory and PendingActions.
+// Actions, cooldowns and pending actions are compared regardless of the order,
+// and Settings are not compared.
+func (g *Game) Diff(other *Game) []string {
+	var r []string
+	diff := func(format string, a ...interface{}) {
+		r = append(r, fmt.Sprintf(format, a...))
+	}
+	if len(g.ActionLogs) != len(other.ActionLogs) {
+		diff("action logs: %d != %d", len(g.ActionLogs), len(other.ActionLogs))
+	}
+	if p, op := pendingByPlayer(g.PendingActions), pendingByPlayer(other.PendingActions); !reflect.DeepEqual(p, op) {
+		diff("pending actions: %v != %v", g.PendingActions, other.PendingActions)
+	}
+	if g.State == nil || other.State == nil {
+		if g.State != other.State {
+			diff("state: %v != %v", g.State, other.State)
+		}
+		return r
+	}
+	if g.State.GameNum != other.State.GameNum {
+		diff("game num: %d != %d", g.State.GameNum, other.State.GameNum)
+	}
+	if g.State.Turns != other.State.Turns {
+		diff("turns: %d != %d", g.State.Turns, other.State.Turns)
+	}
+	for _, ps := range g.State.PlayerStates {
+		ops, found := other.State.PlayerStates.Get(ps.PlayerID)
+		if !found {
+			diff("player %d: missing in other", ps.PlayerID)
+			continue
+		}
+		if ps.Status != ops.Status {
+			diff("player %d: status: %d != %d", ps.PlayerID, ps.Status, ops.Status)
+		}
+		if ps.Points != ops.Points {
+			diff("player %d: points: %d != %d", ps.PlayerID, ps.Points, ops.Points)
+		}
+		if ps.ThinkingTime != ops.ThinkingTime {
+			diff("player %d: thinking time: %v != %v", ps.PlayerID, ps.ThinkingTime, ops.ThinkingTime)
+		}
+		if ps.Unlimited != ops.Unlimited {
+			diff("player %d: unlimited: %t != %t", ps.PlayerID, ps.Unlimited, ops.Unlimited)
+		}
+		if ps.ByoYomiPeriods != ops.ByoYomiPeriods {
+			diff("player %d: byo-yomi periods: %d != %d", ps.PlayerID, ps.ByoYomiPeriods, ops.ByoYomiPeriods)
+		}
+		if ps.Streak != ops.Streak {
+			diff("player %d: streak: %d != %d", ps.PlayerID, ps.Streak, ops.Streak)
+		}
+		if w, ow := g.State.RoundWins[ps.PlayerID], other.State.RoundWins[ps.PlayerID]; w != ow {
+			diff("player %d: round wins: %d != %d", ps.PlayerID, w, ow)
+		}
+		if !ps.Actions.Equal(ops.Actions) {
+			diff("player %d: actions: %v != %v", ps.PlayerID, ps.Actions, ops.Actions)
+		}
+		if !ps.UsedActions.Equal(ops.UsedActions) {
+			diff("player %d: used actions: %v != %v", ps.PlayerID, ps.UsedActions, ops.UsedActions)
+		}
+		if c, oc := sortedCooldowns(ps.Cooldowns), sortedCooldowns(ops.Cooldowns); !reflect.DeepEqual(c, oc) {
+			diff("player %d: cooldowns: %v != %v", ps.PlayerID, ps.Cooldowns, ops.Cooldowns)
+		}
+		if !int32sEqual(ps.PointsHistory, ops.PointsHistory) {
+			diff("player %d: points history: %v != %v", ps.PlayerID, ps.PointsHistory, ops.PointsHistory)
+		}
+	}
+	for _, ops := range other.State.PlayerStates {
+		if _, found := g.State.PlayerStates.Get(ops.PlayerID); !found {
+			diff("player %d: missing", ops.PlayerID)
+		}
+	}
+	return r
+}
+
+func pendingByPlayer(pas PlayerActionSet) map[PlayerID]PlayerAction {
+	r := make(map[PlayerID]PlayerAction, len(pas))
+	for _, pa := range pas {
+		r[pa.PlayerID] = *pa
+	}
+	return r
+}
+
+func int32sEqual(a, b []int32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
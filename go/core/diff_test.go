@@ -0,0 +1,46 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGameDiff(t *testing.T) {
+	g := NewGame(newTestSettings())
+	err := g.ApplyPlayerAction(PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 3}, ThinkingTimeConsumption: time.Second},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 1}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed, err := Replay(g.Settings, g.ActionLogs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !g.Equal(replayed) {
+		t.Fatalf("replayed game should be equal: %v", g.Diff(replayed))
+	}
+	replayed.State.PlayerStates[1].Points++
+	want := []string{"player 2: points: 0 != 1"}
+	if d := g.Diff(replayed); !reflect.DeepEqual(d, want) {
+		t.Errorf("unexpected diff: %q, want %q", d, want)
+	}
+	if g.Equal(replayed) {
+		t.Error("games with different points should not be equal")
+	}
+
+	replayed, _ = Replay(g.Settings, g.ActionLogs)
+	ps := replayed.State.PlayerStates[0]
+	ps.Streak++
+	ps.ByoYomiPeriods++
+	ps.Unlimited = true
+	ps.Cooldowns = append(ps.Cooldowns, Cooldown{Action: Action{Attack, 3}, Rounds: 1})
+	ps.PointsHistory = append(ps.PointsHistory, 9)
+	replayed.State.RoundWins = map[PlayerID]int{1: 1}
+	replayed.PendingActions = PlayerActionSet{{PlayerID: 2, TargetPlayerID: 1, Action: Action{Attack, 1}}}
+	if d := g.Diff(replayed); len(d) != 7 {
+		t.Errorf("unexpected diff: %q", d)
+	}
+}
@@ -0,0 +1,442 @@
+package core
+
+import (
+	"fmt"
+	"time"
+)
+
+// GameDTO is a flat form of Game for code generated types such as protobuf
+// messages. It has only plain fields, and durations are in milliseconds as
+// in JSON. ScoreFunc, ComboBonus, Rand and observers are not converted.
+type GameDTO struct {
+	Settings       *GameSettingsDTO
+	ActionLogs     []ActionSetDTO
+	State          *GameStateDTO
+	Events         []GameEventDTO
+	PendingActions []PlayerActionDTO
+	Seed           int64
+}
+
+type GameSettingsDTO struct {
+	Version                      string
+	Players                      []PlayerDTO
+	TotalGames                   uint32
+	InitialThinkingTimeMs        int64
+	ThinkingTimeIncrementMs      int64
+	Actions                      []ActionDTO
+	JustGuardPoint               int32
+	TimeControlMode              int32
+	VictoryCondition             int32
+	VictoryPoints                int32
+	MaxPoints                    *int32
+	MinPoints                    *int32
+	EndOnMaxPoints               bool
+	ByoYomiPeriods               int64
+	ByoYomiPeriodLengthMs        int64
+	CooldownRounds               uint32
+	InitialPoints                map[uint32]int32
+	PlayerActions                map[uint32]ActionListDTO
+	AllowPass                    bool
+	Teams                        map[uint32]int64
+	FriendlyFire                 bool
+	JustGuardCounter             int32
+	BlockedAttackPenalty         int32
+	RoundAdvancePolicy           int32
+	ResetPointsEachRound         bool
+	MaxThinkingTimeMs            int64
+	AllowSelfTarget              bool
+	AttackClashMode              int32
+	MinThinkingTimeConsumptionMs int64
+	TieBreakers                  []int32
+	TrackUsedActions             bool
+	MaxRounds                    uint32
+	EliminateAtMinPoints         bool
+	CounterMargin                int32
+	ActionsPerRound              int64
+}
+
+type PlayerDTO struct {
+	ID   uint32
+	Name string
+}
+
+type ActionDTO struct {
+	Type  int32
+	Level int32
+}
+
+// ActionListDTO wraps a list to be a map value or a list element.
+type ActionListDTO struct {
+	Actions []ActionDTO
+}
+
+type PlayerActionDTO struct {
+	PlayerID                  uint32
+	TargetPlayerID            uint32
+	Action                    ActionDTO
+	ThinkingTimeConsumptionMs int64
+}
+
+// ActionSetDTO wraps an action set to be a list element.
+type ActionSetDTO struct {
+	Actions []PlayerActionDTO
+}
+
+type GameStateDTO struct {
+	GameNum      uint32
+	PlayerStates []PlayerStateDTO
+	RoundWins    map[uint32]int64
+	Turns        uint32
+}
+
+type PlayerStateDTO struct {
+	PlayerID       uint32
+	Status         int32
+	Points         int32
+	ThinkingTimeMs int64
+	Unlimited      bool
+	ByoYomiPeriods int64
+	Streak         int64
+	Actions        []ActionDTO
+	UsedActions    []ActionDTO
+	Cooldowns      []CooldownDTO
+	PointsHistory  []int32
+}
+
+type CooldownDTO struct {
+	Action ActionDTO
+	Rounds uint32
+}
+
+type GameEventDTO struct {
+	Type           int32
+	Turn           int64
+	GameNum        uint32
+	PlayerID       uint32
+	TargetPlayerID uint32
+	Delta          int32
+}
+
+// ToDTO converts g into a GameDTO sharing no memory with g.
+func (g *Game) ToDTO() *GameDTO {
+	dto := &GameDTO{
+		ActionLogs:     make([]ActionSetDTO, 0, len(g.ActionLogs)),
+		Events:         make([]GameEventDTO, 0, len(g.Events)),
+		PendingActions: playerActionSetToDTO(g.PendingActions),
+		Seed:           g.Seed,
+	}
+	if g.Settings != nil {
+		dto.Settings = g.Settings.toDTO()
+	}
+	for _, pas := range g.ActionLogs {
+		dto.ActionLogs = append(dto.ActionLogs, ActionSetDTO{Actions: playerActionSetToDTO(pas)})
+	}
+	if g.State != nil {
+		dto.State = g.State.toDTO()
+	}
+	for _, ev := range g.Events {
+		dto.Events = append(dto.Events, GameEventDTO{
+			Type:           int32(ev.Type),
+			Turn:           int64(ev.Turn),
+			GameNum:        ev.GameNum,
+			PlayerID:       uint32(ev.PlayerID),
+			TargetPlayerID: uint32(ev.TargetPlayerID),
+			Delta:          ev.Delta,
+		})
+	}
+	return dto
+}
+
+// FromDTO converts dto into a Game, validating it as in LoadGame.
+func FromDTO(dto *GameDTO) (*Game, error) {
+	if dto == nil {
+		return nil, fmt.Errorf("invalid game dto: game not found")
+	}
+	g := &Game{
+		ActionLogs: make([]PlayerActionSet, 0, len(dto.ActionLogs)),
+		Events:     make([]GameEvent, 0, len(dto.Events)),
+		Seed:       dto.Seed,
+	}
+	if dto.Settings != nil {
+		g.Settings = dto.Settings.fromDTO()
+	}
+	for _, set := range dto.ActionLogs {
+		g.ActionLogs = append(g.ActionLogs, playerActionSetFromDTO(set.Actions))
+	}
+	if dto.State != nil {
+		g.State = dto.State.fromDTO()
+	}
+	for _, ev := range dto.Events {
+		g.Events = append(g.Events, GameEvent{
+			Type:           GameEventType(ev.Type),
+			Turn:           int(ev.Turn),
+			GameNum:        ev.GameNum,
+			PlayerID:       PlayerID(ev.PlayerID),
+			TargetPlayerID: PlayerID(ev.TargetPlayerID),
+			Delta:          ev.Delta,
+		})
+	}
+	if len(dto.PendingActions) > 0 {
+		g.PendingActions = playerActionSetFromDTO(dto.PendingActions)
+	}
+	if err := g.validateLoaded(); err != nil {
+		return nil, fmt.Errorf("invalid game dto: %v", err)
+	}
+	if err := g.Settings.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid game dto: %v", err)
+	}
+	return g, nil
+}
+
+func (s *GameSettings) toDTO() *GameSettingsDTO {
+	dto := &GameSettingsDTO{
+		Version:                      s.Version,
+		Players:                      make([]PlayerDTO, 0, len(s.Players)),
+		TotalGames:                   s.TotalGames,
+		InitialThinkingTimeMs:        *toMillis(s.InitialThinkingTime),
+		ThinkingTimeIncrementMs:      *toMillis(s.ThinkingTimeIncrement),
+		Actions:                      actionsToDTO(s.Actions),
+		JustGuardPoint:               s.JustGuardPoint,
+		TimeControlMode:              int32(s.TimeControlMode),
+		VictoryCondition:             int32(s.VictoryCondition),
+		VictoryPoints:                s.VictoryPoints,
+		EndOnMaxPoints:               s.EndOnMaxPoints,
+		ByoYomiPeriods:               int64(s.ByoYomiPeriods),
+		ByoYomiPeriodLengthMs:        *toMillis(s.ByoYomiPeriodLength),
+		CooldownRounds:               s.CooldownRounds,
+		AllowPass:                    s.AllowPass,
+		FriendlyFire:                 s.FriendlyFire,
+		JustGuardCounter:             s.JustGuardCounter,
+		BlockedAttackPenalty:         s.BlockedAttackPenalty,
+		RoundAdvancePolicy:           int32(s.RoundAdvancePolicy),
+		ResetPointsEachRound:         s.ResetPointsEachRound,
+		MaxThinkingTimeMs:            *toMillis(s.MaxThinkingTime),
+		AllowSelfTarget:              s.AllowSelfTarget,
+		AttackClashMode:              int32(s.AttackClashMode),
+		MinThinkingTimeConsumptionMs: *toMillis(s.MinThinkingTimeConsumption),
+		TrackUsedActions:             s.TrackUsedActions,
+		MaxRounds:                    s.MaxRounds,
+		EliminateAtMinPoints:         s.EliminateAtMinPoints,
+		CounterMargin:                s.CounterMargin,
+		ActionsPerRound:              int64(s.ActionsPerRound),
+	}
+	for _, p := range s.Players {
+		dto.Players = append(dto.Players, PlayerDTO{ID: uint32(p.ID), Name: p.Name})
+	}
+	if s.MaxPoints != nil {
+		dto.MaxPoints = PointsLimit(*s.MaxPoints)
+	}
+	if s.MinPoints != nil {
+		dto.MinPoints = PointsLimit(*s.MinPoints)
+	}
+	if s.InitialPoints != nil {
+		dto.InitialPoints = make(map[uint32]int32, len(s.InitialPoints))
+		for id, p := range s.InitialPoints {
+			dto.InitialPoints[uint32(id)] = p
+		}
+	}
+	if s.PlayerActions != nil {
+		dto.PlayerActions = make(map[uint32]ActionListDTO, len(s.PlayerActions))
+		for id, as := range s.PlayerActions {
+			dto.PlayerActions[uint32(id)] = ActionListDTO{Actions: actionsToDTO(as)}
+		}
+	}
+	if s.Teams != nil {
+		dto.Teams = make(map[uint32]int64, len(s.Teams))
+		for id, team := range s.Teams {
+			dto.Teams[uint32(id)] = int64(team)
+		}
+	}
+	for _, tb := range s.TieBreakers {
+		dto.TieBreakers = append(dto.TieBreakers, int32(tb))
+	}
+	return dto
+}
+
+func (dto *GameSettingsDTO) fromDTO() *GameSettings {
+	s := &GameSettings{
+		Version:                    dto.Version,
+		Players:                    make(PlayerSet, 0, len(dto.Players)),
+		TotalGames:                 dto.TotalGames,
+		InitialThinkingTime:        time.Duration(dto.InitialThinkingTimeMs) * time.Millisecond,
+		ThinkingTimeIncrement:      time.Duration(dto.ThinkingTimeIncrementMs) * time.Millisecond,
+		Actions:                    actionsFromDTO(dto.Actions),
+		JustGuardPoint:             dto.JustGuardPoint,
+		TimeControlMode:            TimeControlMode(dto.TimeControlMode),
+		VictoryCondition:           VictoryCondition(dto.VictoryCondition),
+		VictoryPoints:              dto.VictoryPoints,
+		EndOnMaxPoints:             dto.EndOnMaxPoints,
+		ByoYomiPeriods:             int(dto.ByoYomiPeriods),
+		ByoYomiPeriodLength:        time.Duration(dto.ByoYomiPeriodLengthMs) * time.Millisecond,
+		CooldownRounds:             dto.CooldownRounds,
+		AllowPass:                  dto.AllowPass,
+		FriendlyFire:               dto.FriendlyFire,
+		JustGuardCounter:           dto.JustGuardCounter,
+		BlockedAttackPenalty:       dto.BlockedAttackPenalty,
+		RoundAdvancePolicy:         RoundAdvancePolicy(dto.RoundAdvancePolicy),
+		ResetPointsEachRound:       dto.ResetPointsEachRound,
+		MaxThinkingTime:            time.Duration(dto.MaxThinkingTimeMs) * time.Millisecond,
+		AllowSelfTarget:            dto.AllowSelfTarget,
+		AttackClashMode:            AttackClashMode(dto.AttackClashMode),
+		MinThinkingTimeConsumption: time.Duration(dto.MinThinkingTimeConsumptionMs) * time.Millisecond,
+		TrackUsedActions:           dto.TrackUsedActions,
+		MaxRounds:                  dto.MaxRounds,
+		EliminateAtMinPoints:       dto.EliminateAtMinPoints,
+		CounterMargin:              dto.CounterMargin,
+		ActionsPerRound:            int(dto.ActionsPerRound),
+	}
+	for _, p := range dto.Players {
+		s.Players = append(s.Players, &Player{ID: PlayerID(p.ID), Name: p.Name})
+	}
+	if dto.MaxPoints != nil {
+		s.MaxPoints = PointsLimit(*dto.MaxPoints)
+	}
+	if dto.MinPoints != nil {
+		s.MinPoints = PointsLimit(*dto.MinPoints)
+	}
+	if dto.InitialPoints != nil {
+		s.InitialPoints = make(map[PlayerID]int32, len(dto.InitialPoints))
+		for id, p := range dto.InitialPoints {
+			s.InitialPoints[PlayerID(id)] = p
+		}
+	}
+	if dto.PlayerActions != nil {
+		s.PlayerActions = make(map[PlayerID]ActionList, len(dto.PlayerActions))
+		for id, as := range dto.PlayerActions {
+			s.PlayerActions[PlayerID(id)] = actionsFromDTO(as.Actions)
+		}
+	}
+	if dto.Teams != nil {
+		s.Teams = make(map[PlayerID]int, len(dto.Teams))
+		for id, team := range dto.Teams {
+			s.Teams[PlayerID(id)] = int(team)
+		}
+	}
+	for _, tb := range dto.TieBreakers {
+		s.TieBreakers = append(s.TieBreakers, TieBreaker(tb))
+	}
+	return s
+}
+
+func (s *GameState) toDTO() *GameStateDTO {
+	dto := &GameStateDTO{
+		GameNum:      s.GameNum,
+		PlayerStates: make([]PlayerStateDTO, 0, len(s.PlayerStates)),
+		Turns:        s.Turns,
+	}
+	for _, ps := range s.PlayerStates {
+		psDTO := PlayerStateDTO{
+			PlayerID:       uint32(ps.PlayerID),
+			Status:         int32(ps.Status),
+			Points:         ps.Points,
+			ThinkingTimeMs: *toMillis(ps.ThinkingTime),
+			Unlimited:      ps.Unlimited,
+			ByoYomiPeriods: int64(ps.ByoYomiPeriods),
+			Streak:         int64(ps.Streak),
+			Actions:        actionsToDTO(ps.Actions),
+			UsedActions:    actionsToDTO(ps.UsedActions),
+			PointsHistory:  append([]int32(nil), ps.PointsHistory...),
+		}
+		for _, c := range ps.Cooldowns {
+			psDTO.Cooldowns = append(psDTO.Cooldowns, CooldownDTO{Action: actionToDTO(c.Action), Rounds: c.Rounds})
+		}
+		dto.PlayerStates = append(dto.PlayerStates, psDTO)
+	}
+	if s.RoundWins != nil {
+		dto.RoundWins = make(map[uint32]int64, len(s.RoundWins))
+		for id, n := range s.RoundWins {
+			dto.RoundWins[uint32(id)] = int64(n)
+		}
+	}
+	return dto
+}
+
+func (dto *GameStateDTO) fromDTO() *GameState {
+	s := &GameState{
+		GameNum:      dto.GameNum,
+		PlayerStates: make(PlayerStateSet, 0, len(dto.PlayerStates)),
+		Turns:        dto.Turns,
+	}
+	for _, psDTO := range dto.PlayerStates {
+		ps := &PlayerState{
+			PlayerID:       PlayerID(psDTO.PlayerID),
+			Status:         PlayerStatus(psDTO.Status),
+			Points:         psDTO.Points,
+			ThinkingTime:   time.Duration(psDTO.ThinkingTimeMs) * time.Millisecond,
+			Unlimited:      psDTO.Unlimited,
+			ByoYomiPeriods: int(psDTO.ByoYomiPeriods),
+			Streak:         int(psDTO.Streak),
+			Actions:        actionsFromDTO(psDTO.Actions),
+		}
+		if len(psDTO.UsedActions) > 0 {
+			ps.UsedActions = actionsFromDTO(psDTO.UsedActions)
+		}
+		for _, c := range psDTO.Cooldowns {
+			ps.Cooldowns = append(ps.Cooldowns, Cooldown{Action: actionFromDTO(c.Action), Rounds: c.Rounds})
+		}
+		if len(psDTO.PointsHistory) > 0 {
+			ps.PointsHistory = append([]int32(nil), psDTO.PointsHistory...)
+		}
+		s.PlayerStates = append(s.PlayerStates, ps)
+	}
+	if dto.RoundWins != nil {
+		s.RoundWins = make(map[PlayerID]int, len(dto.RoundWins))
+		for id, n := range dto.RoundWins {
+			s.RoundWins[PlayerID(id)] = int(n)
+		}
+	}
+	return s
+}
+
+func actionToDTO(a Action) ActionDTO {
+	return ActionDTO{Type: int32(a.Type), Level: int32(a.Level)}
+}
+
+func actionFromDTO(dto ActionDTO) Action {
+	return Action{Type: ActionType(dto.Type), Level: ActionLevel(dto.Level)}
+}
+
+func actionsToDTO(as ActionList) []ActionDTO {
+	if as == nil {
+		return nil
+	}
+	r := make([]ActionDTO, 0, len(as))
+	for _, a := range as {
+		r = append(r, actionToDTO(a))
+	}
+	return r
+}
+
+func actionsFromDTO(dtos []ActionDTO) ActionList {
+	r := make(ActionList, 0, len(dtos))
+	for _, dto := range dtos {
+		r = append(r, actionFromDTO(dto))
+	}
+	return r
+}
+
+func playerActionSetToDTO(pas PlayerActionSet) []PlayerActionDTO {
+	r := make([]PlayerActionDTO, 0, len(pas))
+	for _, pa := range pas {
+		r = append(r, PlayerActionDTO{
+			PlayerID:                  uint32(pa.PlayerID),
+			TargetPlayerID:            uint32(pa.TargetPlayerID),
+			Action:                    actionToDTO(pa.Action),
+			ThinkingTimeConsumptionMs: *toMillis(pa.ThinkingTimeConsumption),
+		})
+	}
+	return r
+}
+
+func playerActionSetFromDTO(dtos []PlayerActionDTO) PlayerActionSet {
+	r := make(PlayerActionSet, 0, len(dtos))
+	for _, dto := range dtos {
+		r = append(r, &PlayerAction{
+			PlayerID:                PlayerID(dto.PlayerID),
+			TargetPlayerID:          PlayerID(dto.TargetPlayerID),
+			Action:                  actionFromDTO(dto.Action),
+			ThinkingTimeConsumption: time.Duration(dto.ThinkingTimeConsumptionMs) * time.Millisecond,
+		})
+	}
+	return r
+}
@@ -0,0 +1,74 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDTO(t *testing.T) {
+	settings := newTestSettings()
+	settings.TotalGames = 2
+	settings.MaxPoints = PointsLimit(10)
+	settings.InitialPoints = map[PlayerID]int32{1: 2}
+	settings.TieBreakers = []TieBreaker{MoreThinkingTime}
+	g := NewGameWithSeed(settings, 42)
+	err := g.ApplyPlayerAction(PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 3}, ThinkingTimeConsumption: 1500 * time.Millisecond},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 1}, ThinkingTimeConsumption: 2 * time.Second},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.SubmitAction(&PlayerAction{PlayerID: 1, TargetPlayerID: 2, Action: Action{Defence, 2}}); err != nil {
+		t.Fatal(err)
+	}
+	dto := g.ToDTO()
+	if dto.State.PlayerStates[0].ThinkingTimeMs != 13500 || *dto.Settings.MaxPoints != 10 {
+		t.Fatalf("unexpected dto: %+v", dto)
+	}
+	decoded, err := FromDTO(dto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := g.Diff(decoded); len(d) != 0 {
+		t.Fatalf("unexpected differences: %v", d)
+	}
+	if !reflect.DeepEqual(g.ActionLogs, decoded.ActionLogs) || !reflect.DeepEqual(g.Events, decoded.Events) ||
+		!reflect.DeepEqual(g.PendingActions, decoded.PendingActions) {
+		t.Fatal("logs, events or pending actions differ")
+	}
+	if !reflect.DeepEqual(g.Settings, decoded.Settings) || decoded.Rand == nil {
+		t.Fatalf("unexpected game: %+v", decoded)
+	}
+	*dto.Settings.MaxPoints = 20
+	if *g.Settings.MaxPoints != 10 {
+		t.Error("dto shares memory with game")
+	}
+
+	pa := &PlayerAction{PlayerID: 2, TargetPlayerID: 1, Action: Action{Attack, 2}}
+	for _, game := range []*Game{g, decoded} {
+		if _, err := game.SubmitAction(pa); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if d := g.Diff(decoded); len(d) != 0 {
+		t.Fatalf("unexpected differences after action: %v", d)
+	}
+}
+
+func TestFromDTOInvalid(t *testing.T) {
+	if _, err := FromDTO(nil); err == nil {
+		t.Error("nil dto accepted")
+	}
+	dto := NewGame(newTestSettings()).ToDTO()
+	dto.State = nil
+	if _, err := FromDTO(dto); err == nil {
+		t.Error("dto without state accepted")
+	}
+	dto = NewGame(newTestSettings()).ToDTO()
+	dto.Settings.Actions = nil
+	if _, err := FromDTO(dto); err == nil {
+		t.Error("dto with invalid settings accepted")
+	}
+}
@@ -0,0 +1,56 @@
+package core
+
+import "math"
+
+// DefaultEloRating is the rating of players who are not rated yet.
+const DefaultEloRating = 1500
+
+// UpdateElo returns the new ratings of A and B after a game where A scored
+// scoreA, i.e. 1 for a win, 0.5 for a draw and 0 for a loss. k is the maximum
+// change of a rating per game.
+func UpdateElo(ratingA, ratingB float64, scoreA float64, k float64) (newA, newB float64) {
+	expectedA := 1 / (1 + math.Pow(10, (ratingB-ratingA)/400))
+	delta := k * (scoreA - expectedA)
+	return ratingA + delta, ratingB - delta
+}
+
+// EloFromGame returns the ratings updated by the result of g. Every pair of
+// the players is rated as a game where the winner scores 1 and otherwise
+// both draw. Players missing in ratings start at DefaultEloRating.
+// If g is not over, the ratings are returned unchanged.
+// ratings itself is not modified.
+func EloFromGame(g *Game, ratings map[PlayerID]float64, k float64) map[PlayerID]float64 {
+	r := make(map[PlayerID]float64, len(ratings))
+	for id, rating := range ratings {
+		r[id] = rating
+	}
+	if !g.IsGameOver() {
+		return r
+	}
+	old := make(map[PlayerID]float64, len(g.Settings.Players))
+	for _, p := range g.Settings.Players {
+		rating, found := ratings[p.ID]
+		if !found {
+			rating = DefaultEloRating
+		}
+		old[p.ID] = rating
+		r[p.ID] = rating
+	}
+	winner, hasWinner := g.GetWinner()
+	players := g.Settings.Players
+	for i := range players {
+		for j := i + 1; j < len(players); j++ {
+			a, b := players[i].ID, players[j].ID
+			scoreA := 0.5
+			if hasWinner && winner.ID == a {
+				scoreA = 1
+			} else if hasWinner && winner.ID == b {
+				scoreA = 0
+			}
+			newA, newB := UpdateElo(old[a], old[b], scoreA, k)
+			r[a] += newA - old[a]
+			r[b] += newB - old[b]
+		}
+	}
+	return r
+}
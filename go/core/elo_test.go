@@ -0,0 +1,41 @@
+package core
+
+import (
+	"math"
+	"testing"
+)
+
+func TestUpdateElo(t *testing.T) {
+	for _, c := range []struct {
+		a, b, score, k, newA, newB float64
+	}{
+		{1500, 1500, 1, 32, 1516, 1484},
+		{1500, 1500, 0.5, 32, 1500, 1500},
+		{1613, 1477, 0, 32, 1591.0384, 1498.9616},
+		{2000, 1600, 1, 20, 2001.8182, 1598.1818},
+	} {
+		newA, newB := UpdateElo(c.a, c.b, c.score, c.k)
+		if math.Abs(newA-c.newA) > 1e-3 || math.Abs(newB-c.newB) > 1e-3 {
+			t.Errorf("%v: unexpected ratings: %v, %v", c, newA, newB)
+		}
+	}
+}
+
+func TestEloFromGame(t *testing.T) {
+	g := NewGame(newTestSettings())
+	ratings := map[PlayerID]float64{1: 1600}
+	if r := EloFromGame(g, ratings, 32); r[1] != 1600 || len(r) != 1 {
+		t.Fatalf("ratings should not change before the game is over: %v", r)
+	}
+	if err := g.Timeout(1); err != nil {
+		t.Fatal(err)
+	}
+	r := EloFromGame(g, ratings, 32)
+	newB, newA := UpdateElo(DefaultEloRating, 1600, 1, 32)
+	if math.Abs(r[1]-newA) > 1e-9 || math.Abs(r[2]-newB) > 1e-9 {
+		t.Errorf("unexpected ratings: %v", r)
+	}
+	if ratings[1] != 1600 || len(ratings) != 1 {
+		t.Errorf("ratings were mutated: %v", ratings)
+	}
+}
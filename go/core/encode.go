@@ -0,0 +1,109 @@
+package core
+
+// Encode returns the features of the current state seen by playerID for
+// machine learning. The opponent is the other player with the highest points,
+// the first one in Settings.Players on a tie. With L the highest level of all
+// action pools, the layout of the 5+8L values is:
+//
+//	[0] own points
+//	[1] opponent points
+//	[2] own thinking time / InitialThinkingTime (1 if unlimited)
+//	[3] opponent thinking time / InitialThinkingTime (1 if unlimited)
+//	[4] (GameNum - 1) / TotalGames, or 1 if the game is over
+//	[5, 5+L) own attacks of levels 1 to L
+//	[5+L, 5+2L) own defences of levels 1 to L
+//	[5+2L, 5+3L) own swaps of levels 1 to L
+//	[5+3L, 5+4L) own counters of levels 1 to L
+//	[5+4L, 5+8L) opponent actions in the same order as own ones
+//
+// It returns nil if playerID is not found.
+func (g *Game) Encode(playerID PlayerID) []float32 {
+	if g.State == nil {
+		return nil
+	}
+	own, found := g.State.PlayerStates.Get(playerID)
+	if !found {
+		return nil
+	}
+	var opponent *PlayerState
+	for _, p := range g.Settings.Players {
+		ps, found := g.State.PlayerStates.Get(p.ID)
+		if !found || p.ID == playerID {
+			continue
+		}
+		if opponent == nil || ps.Points > opponent.Points {
+			opponent = ps
+		}
+	}
+	if opponent == nil {
+		opponent = &PlayerState{}
+	}
+	maxLevel := g.maxActionLevel()
+	r := make([]float32, 5+2*encodedActionSlots*maxLevel)
+	r[0] = float32(own.Points)
+	r[1] = float32(opponent.Points)
+	r[2] = g.normalizedThinkingTime(own)
+	r[3] = g.normalizedThinkingTime(opponent)
+	if g.State.IsGameOver() {
+		r[4] = 1
+	} else {
+		r[4] = float32(g.State.GameNum-1) / float32(g.Settings.TotalGames)
+	}
+	for i, ps := range []*PlayerState{own, opponent} {
+		for _, a := range ps.AvailableActions() {
+			if a.Level < 1 || int(a.Level) > maxLevel {
+				continue
+			}
+			slot := encodedActionSlot(a.Type)
+			if slot < 0 {
+				continue
+			}
+			offset := 5 + (i*encodedActionSlots+slot)*maxLevel
+			r[offset+int(a.Level)-1]++
+		}
+	}
+	return r
+}
+
+// encodedActionSlots is the number of the action types encoded by Encode.
+const encodedActionSlots = 4
+
+// encodedActionSlot returns the index of t among the action types of Encode,
+// or -1 if t is not encoded.
+func encodedActionSlot(t ActionType) int {
+	switch t {
+	case Attack:
+		return 0
+	case Defence:
+		return 1
+	case Swap:
+		return 2
+	case Counter:
+		return 3
+	default:
+		return -1
+	}
+}
+
+func (g *Game) maxActionLevel() int {
+	r := 0
+	check := func(as ActionList) {
+		for _, a := range as {
+			if int(a.Level) > r {
+				r = int(a.Level)
+			}
+		}
+	}
+	check(g.Settings.Actions)
+	for _, as := range g.Settings.PlayerActions {
+		check(as)
+	}
+	return r
+}
+
+func (g *Game) normalizedThinkingTime(ps *PlayerState) float32 {
+	if ps.Unlimited || g.Settings.InitialThinkingTime <= 0 {
+		return 1
+	}
+	return float32(ps.ThinkingTime) / float32(g.Settings.InitialThinkingTime)
+}
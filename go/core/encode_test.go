@@ -0,0 +1,49 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEncode(t *testing.T) {
+	g := NewGame(newTestSettings())
+	initial := g.Encode(1)
+	if len(initial) != 5+8*3 {
+		t.Fatalf("unexpected length: %d", len(initial))
+	}
+	err := g.ApplyPlayerAction(PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 3}, ThinkingTimeConsumption: 10 * time.Second},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 1}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := g.Encode(1)
+	want := []float32{
+		2, 0, 0.5, 1.5, 0,
+		1, 1, 0, 1, 1, 1, 0, 0, 0, 0, 0, 0,
+		1, 1, 1, 0, 1, 1, 0, 0, 0, 0, 0, 0,
+	}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("unexpected features: %v, want %v", v, want)
+	}
+	if !reflect.DeepEqual(g.Clone().Encode(1), v) {
+		t.Error("identical states should encode identically")
+	}
+	if g.Encode(3) != nil {
+		t.Error("unknown player should not be encoded")
+	}
+
+	settings := newTestSettings()
+	settings.Actions = ActionList{{Attack, 1}, {Counter, 2}}
+	counter := NewGame(settings).Encode(1)
+	settings.Actions = ActionList{{Attack, 1}, {Swap, 2}}
+	swap := NewGame(settings).Encode(1)
+	if reflect.DeepEqual(counter, swap) {
+		t.Errorf("counters and swaps should encode differently: %v", counter)
+	}
+	if counter[5+3*2+1] != 1 || swap[5+2*2+1] != 1 {
+		t.Errorf("unexpected features: %v, %v", counter, swap)
+	}
+}
@@ -0,0 +1,50 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors returned by the game. They can be tested by errors.Is.
+var (
+	ErrGameOver             = errors.New("game was over")
+	ErrInvalidActionSetSize = errors.New("invalid size of player action set")
+	ErrPlayerNotFound       = errors.New("player not found")
+	ErrActionUnavailable    = errors.New("unavailable action")
+	ErrOverThinkingTime     = errors.New("over thinking time")
+	ErrUnderThinkingTime    = errors.New("under minimum thinking time consumption")
+	ErrFriendlyFire         = errors.New("attack on teammate")
+	ErrSelfTarget           = errors.New("attack on self")
+	ErrRevealMismatch       = errors.New("revealed action does not match commit")
+	ErrGameNotFound         = errors.New("game not found")
+	ErrPlayerEliminated     = errors.New("player was eliminated")
+)
+
+// PlayerNotFoundError means no player or player state has PlayerID.
+// It matches ErrPlayerNotFound.
+type PlayerNotFoundError struct {
+	PlayerID PlayerID
+}
+
+func (e *PlayerNotFoundError) Error() string {
+	return fmt.Sprintf("player (id: %d) not found", e.PlayerID)
+}
+
+func (e *PlayerNotFoundError) Is(target error) bool {
+	return target == ErrPlayerNotFound
+}
+
+// TimeoutError means the player consumed more time than it had. The action set
+// is rejected, so callers may end the game by Game.Timeout.
+// It matches ErrOverThinkingTime.
+type TimeoutError struct {
+	PlayerID PlayerID
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("player (id: %d) is over thinking time", e.PlayerID)
+}
+
+func (e *TimeoutError) Is(target error) bool {
+	return target == ErrOverThinkingTime
+}
@@ -0,0 +1,85 @@
+package core
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTimeoutError(t *testing.T) {
+	g := NewGame(newTestSettings())
+	err := g.ApplyPlayerAction(PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 1}, ThinkingTimeConsumption: time.Second},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Attack, 1}, ThinkingTimeConsumption: time.Minute},
+	})
+	var te *TimeoutError
+	if !errors.As(err, &te) || te.PlayerID != 2 {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(g.ActionLogs) != 0 {
+		t.Fatal("action set should be rejected")
+	}
+	if err := g.Timeout(te.PlayerID); err != nil {
+		t.Fatal(err)
+	}
+	if p, ok := g.GetWinner(); !ok || p.ID != 1 {
+		t.Errorf("unexpected winner: %v", p)
+	}
+	_, err = g.Clone().SubmitAction(&PlayerAction{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 1}, ThinkingTimeConsumption: time.Minute})
+	if errors.As(err, &te) {
+		t.Errorf("game over should not be a timeout: %v", err)
+	}
+}
+
+func TestTimeoutErrorOnSubmit(t *testing.T) {
+	g := NewGame(newTestSettings())
+	_, err := g.SubmitAction(&PlayerAction{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 1}, ThinkingTimeConsumption: time.Minute})
+	var te *TimeoutError
+	if !errors.As(err, &te) || te.PlayerID != 1 {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := Replay(g.Settings, []PlayerActionSet{{
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 1}},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Attack, 1}, ThinkingTimeConsumption: time.Minute},
+	}}); !errors.As(err, &te) || te.PlayerID != 2 {
+		t.Errorf("unexpected replay error: %v", err)
+	}
+}
+
+func TestApplyPlayerActionErrors(t *testing.T) {
+	a := Action{Attack, 1}
+	var pnf *PlayerNotFoundError
+	for _, tc := range []struct {
+		name string
+		pas  PlayerActionSet
+		want error
+	}{
+		{"size", PlayerActionSet{{PlayerID: 1, TargetPlayerID: 2, Action: a}}, ErrInvalidActionSetSize},
+		{"target", PlayerActionSet{{PlayerID: 1, TargetPlayerID: 3, Action: a}, {PlayerID: 2, TargetPlayerID: 1, Action: a}}, ErrPlayerNotFound},
+		{"unavailable", PlayerActionSet{{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 4}}, {PlayerID: 2, TargetPlayerID: 1, Action: a}}, ErrActionUnavailable},
+		{"time", PlayerActionSet{{PlayerID: 1, TargetPlayerID: 2, Action: a, ThinkingTimeConsumption: time.Minute}, {PlayerID: 2, TargetPlayerID: 1, Action: a}}, ErrOverThinkingTime},
+	} {
+		g := NewGame(newTestSettings())
+		if err := g.ApplyPlayerAction(tc.pas); !errors.Is(err, tc.want) {
+			t.Errorf("%s: unexpected error: %v, want %v", tc.name, err, tc.want)
+		} else if tc.want == ErrPlayerNotFound && (!errors.As(err, &pnf) || pnf.PlayerID != 3) {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+		}
+	}
+	g := NewGame(newTestSettings())
+	if err := g.Forfeit(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Forfeit(2); !errors.Is(err, ErrGameOver) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := g.Timeout(2); !errors.Is(err, ErrGameOver) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := NewGame(newTestSettings()).Timeout(3); !errors.As(err, &pnf) || pnf.PlayerID != 3 {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := NewGame(newTestSettings()).SubmitAction(&PlayerAction{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 4}}); !errors.Is(err, ErrActionUnavailable) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,50 @@
+package core
+
+import "fmt"
+
+type GameEventType int8
+
+const (
+	// PointsAwardedEvent means PlayerID gained Delta points by its action
+	// against TargetPlayerID or by the action of TargetPlayerID.
+	PointsAwardedEvent GameEventType = iota
+	// JustGuardEvent means PlayerID guarded the attack of TargetPlayerID with
+	// a defence of the same level and gained Delta points.
+	JustGuardEvent
+	// RoundAdvancedEvent means the next game started.
+	RoundAdvancedEvent
+	// GameOverEvent means the game was over. PlayerID is the player who timed
+	// out or forfeited if any.
+	GameOverEvent
+	// EliminatedEvent means PlayerID was eliminated.
+	EliminatedEvent
+)
+
+func (t GameEventType) String() string {
+	switch t {
+	case PointsAwardedEvent:
+		return "PointsAwarded"
+	case JustGuardEvent:
+		return "JustGuard"
+	case RoundAdvancedEvent:
+		return "RoundAdvanced"
+	case GameOverEvent:
+		return "GameOver"
+	case EliminatedEvent:
+		return "Eliminated"
+	default:
+		return fmt.Sprintf("GameEventType(%d)", int8(t))
+	}
+}
+
+// GameEvent describes what happened by applying an action set.
+type GameEvent struct {
+	Type GameEventType `json:"type"`
+	// Turn is the index of the action set in ActionLogs.
+	Turn int `json:"turn"`
+	// GameNum is the game in which the event happened.
+	GameNum        uint32   `json:"gameNum"`
+	PlayerID       PlayerID `json:"playerId,omitempty"`
+	TargetPlayerID PlayerID `json:"targetPlayerId,omitempty"`
+	Delta          int32    `json:"delta,omitempty"`
+}
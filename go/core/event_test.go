@@ -0,0 +1,51 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEvents(t *testing.T) {
+	settings := newTestSettings()
+	settings.TotalGames = 2
+	settings.Actions = ActionList{{Attack, 2}, {Defence, 2}}
+	g := NewGame(settings)
+	for _, pas := range []PlayerActionSet{
+		{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 2}},
+			{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 2}},
+		},
+		{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Defence, 2}},
+			{PlayerID: 2, TargetPlayerID: 1, Action: Action{Attack, 2}},
+		},
+		{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 2}},
+			{PlayerID: 2, TargetPlayerID: 1, Action: Action{Attack, 2}},
+		},
+	} {
+		if err := g.ApplyPlayerAction(pas); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := g.Forfeit(2); err != nil {
+		t.Fatal(err)
+	}
+	want := []GameEvent{
+		{Type: JustGuardEvent, Turn: 0, GameNum: 1, PlayerID: 2, TargetPlayerID: 1, Delta: 3},
+		{Type: JustGuardEvent, Turn: 1, GameNum: 1, PlayerID: 1, TargetPlayerID: 2, Delta: 3},
+		{Type: RoundAdvancedEvent, Turn: 1, GameNum: 1},
+		{Type: PointsAwardedEvent, Turn: 2, GameNum: 2, PlayerID: 1, TargetPlayerID: 2, Delta: 2},
+		{Type: PointsAwardedEvent, Turn: 2, GameNum: 2, PlayerID: 2, TargetPlayerID: 1, Delta: 2},
+		{Type: GameOverEvent, Turn: 3, GameNum: 2, PlayerID: 2},
+	}
+	if !reflect.DeepEqual(g.Events, want) {
+		t.Fatalf("unexpected events: %+v", g.Events)
+	}
+	if err := g.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(g.Events, want[:5]) {
+		t.Fatalf("unexpected events after undo: %+v", g.Events)
+	}
+}
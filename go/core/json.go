@@ -0,0 +1,208 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Durations are encoded in JSON as integer milliseconds under keys with an
+// "Ms" suffix. The original keys holding nanoseconds are still decoded so
+// that games saved by older versions can be loaded.
+
+// toMillis truncates d to milliseconds. A positive duration shorter than a
+// millisecond is rounded up to avoid encoding it as InfiniteThinkingTime.
+func toMillis(d time.Duration) *int64 {
+	ms := int64(d / time.Millisecond)
+	if ms == 0 && d > 0 {
+		ms = 1
+	}
+	return &ms
+}
+
+// optionalMillis is like toMillis but returns nil for 0 to omit the field.
+func optionalMillis(d time.Duration) *int64 {
+	if d == 0 {
+		return nil
+	}
+	return toMillis(d)
+}
+
+func fromMillis(ms *int64, legacy *time.Duration, d *time.Duration) {
+	if ms != nil {
+		*d = time.Duration(*ms) * time.Millisecond
+	} else if legacy != nil {
+		*d = *legacy
+	}
+}
+
+type gameSettingsAlias GameSettings
+
+type gameSettingsJSON struct {
+	*gameSettingsAlias
+	InitialThinkingTime              *time.Duration `json:"initialThinkingTime,omitempty"`
+	ThinkingTimeIncrement            *time.Duration `json:"thinkingTimeIncrement,omitempty"`
+	ByoYomiPeriodLength              *time.Duration `json:"byoYomiPeriodLength,omitempty"`
+	MaxThinkingTime                  *time.Duration `json:"maxThinkingTime,omitempty"`
+	MinThinkingTimeConsumption       *time.Duration `json:"minThinkingTimeConsumption,omitempty"`
+	InitialThinkingTimeMillis        *int64         `json:"initialThinkingTimeMs"`
+	ThinkingTimeIncrementMillis      *int64         `json:"thinkingTimeIncrementMs"`
+	ByoYomiPeriodLengthMillis        *int64         `json:"byoYomiPeriodLengthMs,omitempty"`
+	MaxThinkingTimeMillis            *int64         `json:"maxThinkingTimeMs,omitempty"`
+	MinThinkingTimeConsumptionMillis *int64         `json:"minThinkingTimeConsumptionMs,omitempty"`
+}
+
+func (s GameSettings) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&gameSettingsJSON{
+		gameSettingsAlias:                (*gameSettingsAlias)(&s),
+		InitialThinkingTimeMillis:        toMillis(s.InitialThinkingTime),
+		ThinkingTimeIncrementMillis:      toMillis(s.ThinkingTimeIncrement),
+		ByoYomiPeriodLengthMillis:        optionalMillis(s.ByoYomiPeriodLength),
+		MaxThinkingTimeMillis:            optionalMillis(s.MaxThinkingTime),
+		MinThinkingTimeConsumptionMillis: optionalMillis(s.MinThinkingTimeConsumption),
+	})
+}
+
+func (s *GameSettings) UnmarshalJSON(data []byte) error {
+	v := gameSettingsJSON{gameSettingsAlias: (*gameSettingsAlias)(s)}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	fromMillis(v.InitialThinkingTimeMillis, v.InitialThinkingTime, &s.InitialThinkingTime)
+	fromMillis(v.ThinkingTimeIncrementMillis, v.ThinkingTimeIncrement, &s.ThinkingTimeIncrement)
+	fromMillis(v.ByoYomiPeriodLengthMillis, v.ByoYomiPeriodLength, &s.ByoYomiPeriodLength)
+	fromMillis(v.MaxThinkingTimeMillis, v.MaxThinkingTime, &s.MaxThinkingTime)
+	fromMillis(v.MinThinkingTimeConsumptionMillis, v.MinThinkingTimeConsumption, &s.MinThinkingTimeConsumption)
+	return nil
+}
+
+type playerStateAlias PlayerState
+
+type playerStateJSON struct {
+	*playerStateAlias
+	ThinkingTime       *time.Duration `json:"thinkingTime,omitempty"`
+	ThinkingTimeMillis *int64         `json:"thinkingTimeMs"`
+}
+
+func (s PlayerState) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&playerStateJSON{
+		playerStateAlias:   (*playerStateAlias)(&s),
+		ThinkingTimeMillis: toMillis(s.ThinkingTime),
+	})
+}
+
+func (s *PlayerState) UnmarshalJSON(data []byte) error {
+	v := playerStateJSON{playerStateAlias: (*playerStateAlias)(s)}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	fromMillis(v.ThinkingTimeMillis, v.ThinkingTime, &s.ThinkingTime)
+	return nil
+}
+
+type playerActionAlias PlayerAction
+
+type playerActionJSON struct {
+	*playerActionAlias
+	ThinkingTimeConsumption       *time.Duration `json:"thinkingTimeConsumption,omitempty"`
+	ThinkingTimeConsumptionMillis *int64         `json:"thinkingTimeConsumptionMs"`
+}
+
+func (pa PlayerAction) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&playerActionJSON{
+		playerActionAlias:             (*playerActionAlias)(&pa),
+		ThinkingTimeConsumptionMillis: toMillis(pa.ThinkingTimeConsumption),
+	})
+}
+
+func (pa *PlayerAction) UnmarshalJSON(data []byte) error {
+	v := playerActionJSON{playerActionAlias: (*playerActionAlias)(pa)}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	fromMillis(v.ThinkingTimeConsumptionMillis, v.ThinkingTimeConsumption, &pa.ThinkingTimeConsumption)
+	return nil
+}
+
+type playerScoreAlias PlayerScore
+
+type playerScoreJSON struct {
+	*playerScoreAlias
+	ThinkingTime       *time.Duration `json:"thinkingTime,omitempty"`
+	ThinkingTimeMillis *int64         `json:"thinkingTimeMs"`
+}
+
+func (s PlayerScore) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&playerScoreJSON{
+		playerScoreAlias:   (*playerScoreAlias)(&s),
+		ThinkingTimeMillis: toMillis(s.ThinkingTime),
+	})
+}
+
+func (s *PlayerScore) UnmarshalJSON(data []byte) error {
+	v := playerScoreJSON{playerScoreAlias: (*playerScoreAlias)(s)}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	fromMillis(v.ThinkingTimeMillis, v.ThinkingTime, &s.ThinkingTime)
+	return nil
+}
+
+// ExampleGameSettings returns valid settings with the typical values, which
+// show the shape of the JSON payload.
+func ExampleGameSettings() *GameSettings {
+	return &GameSettings{
+		Version: Version,
+		Players: PlayerSet{
+			{ID: 1, Name: "Alice"},
+			{ID: 2, Name: "Bob"},
+		},
+		TotalGames:            3,
+		InitialThinkingTime:   3 * time.Minute,
+		ThinkingTimeIncrement: 5 * time.Second,
+		Actions: ActionList{
+			{Attack, 1}, {Attack, 2}, {Attack, 3},
+			{Defence, 1}, {Defence, 2}, {Defence, 3},
+		},
+		JustGuardPoint: 3,
+	}
+}
+
+// MarshalIndent returns the indented JSON of s.
+func (s *GameSettings) MarshalIndent() ([]byte, error) {
+	return json.MarshalIndent(s, "", "  ")
+}
+
+// MarshalText encodes t by its name, or by its integer value if unknown.
+func (t ActionType) MarshalText() ([]byte, error) {
+	switch t {
+	case Attack, Defence, Forfeit, Pass, Swap, Counter, Eliminate, Timeout:
+		return []byte(t.String()), nil
+	default:
+		return []byte(strconv.Itoa(int(t))), nil
+	}
+}
+
+func (t *ActionType) UnmarshalText(text []byte) error {
+	parsed, err := ParseActionType(string(text))
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
+
+// UnmarshalJSON accepts a JSON number as well as a name for compatibility.
+func (t *ActionType) UnmarshalJSON(data []byte) error {
+	var n int8
+	if err := json.Unmarshal(data, &n); err == nil {
+		*t = ActionType(n)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("invalid action type: %s", data)
+	}
+	return t.UnmarshalText([]byte(s))
+}
@@ -0,0 +1,150 @@
+package core
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGameSettingsJSON(t *testing.T) {
+	for _, tc := range []struct {
+		in, out time.Duration
+	}{
+		{InfiniteThinkingTime, InfiniteThinkingTime},
+		{1500 * time.Millisecond, 1500 * time.Millisecond},
+		{time.Millisecond + 300*time.Microsecond, time.Millisecond},
+		{300 * time.Microsecond, time.Millisecond},
+	} {
+		settings := newTestSettings()
+		settings.InitialThinkingTime = tc.in
+		settings.ThinkingTimeIncrement = tc.in
+		data, err := json.Marshal(settings)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), `"initialThinkingTime"`) {
+			t.Fatalf("nanoseconds were encoded: %s", data)
+		}
+		var decoded GameSettings
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatal(err)
+		}
+		if decoded.InitialThinkingTime != tc.out || decoded.ThinkingTimeIncrement != tc.out {
+			t.Errorf("%v: unexpected durations: %v, %v", tc.in, decoded.InitialThinkingTime, decoded.ThinkingTimeIncrement)
+		}
+		if len(decoded.Players) != 2 || len(decoded.Actions) != 6 || decoded.JustGuardPoint != 3 {
+			t.Errorf("unexpected settings: %+v", decoded)
+		}
+	}
+}
+
+func TestPlayerStateJSON(t *testing.T) {
+	ps := &PlayerState{PlayerID: 1, Points: 3, ThinkingTime: 2500 * time.Millisecond, Actions: ActionList{{Attack, 1}}}
+	data, err := json.Marshal(ps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"thinkingTimeMs":2500`) {
+		t.Fatalf("unexpected json: %s", data)
+	}
+	var decoded PlayerState
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.ThinkingTime != ps.ThinkingTime || decoded.Points != 3 || len(decoded.Actions) != 1 {
+		t.Fatalf("unexpected state: %+v", decoded)
+	}
+}
+
+func TestPlayerScoreJSON(t *testing.T) {
+	r := &GameResult{Scores: []PlayerScore{{PlayerID: 1, Name: "P1", Points: 3, ThinkingTime: 2500 * time.Millisecond}}}
+	data, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"thinkingTimeMs":2500`) || strings.Contains(string(data), `"thinkingTime":`) {
+		t.Fatalf("unexpected json: %s", data)
+	}
+	var decoded GameResult
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&decoded, r) {
+		t.Fatalf("unexpected result: %+v", decoded)
+	}
+	var legacy PlayerScore
+	if err := json.Unmarshal([]byte(`{"playerId":1,"thinkingTime":2500000000}`), &legacy); err != nil {
+		t.Fatal(err)
+	}
+	if legacy.ThinkingTime != 2500*time.Millisecond {
+		t.Fatalf("unexpected score: %+v", legacy)
+	}
+}
+
+func TestLegacyDurationJSON(t *testing.T) {
+	var settings GameSettings
+	if err := json.Unmarshal([]byte(`{"initialThinkingTime":10000000000,"thinkingTimeIncrement":5000000000}`), &settings); err != nil {
+		t.Fatal(err)
+	}
+	if settings.InitialThinkingTime != 10*time.Second || settings.ThinkingTimeIncrement != 5*time.Second {
+		t.Fatalf("unexpected settings: %+v", settings)
+	}
+	var pa PlayerAction
+	if err := json.Unmarshal([]byte(`{"playerId":1,"thinkingTimeConsumption":500}`), &pa); err != nil {
+		t.Fatal(err)
+	}
+	if pa.PlayerID != 1 || pa.ThinkingTimeConsumption != 500 {
+		t.Fatalf("unexpected action: %+v", pa)
+	}
+}
+
+func TestExampleGameSettings(t *testing.T) {
+	settings := ExampleGameSettings()
+	if err := settings.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := settings.MarshalIndent()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "\n  \"initialThinkingTimeMs\": 180000") {
+		t.Errorf("unexpected JSON: %s", data)
+	}
+	var decoded GameSettings
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&decoded, settings) {
+		t.Errorf("unexpected decoded settings: %+v", decoded)
+	}
+}
+
+func TestActionTypeText(t *testing.T) {
+	data, err := json.Marshal(Action{Attack, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"Type":"Attack","Level":2}` {
+		t.Errorf("unexpected JSON: %s", data)
+	}
+	for _, tc := range []struct {
+		in   string
+		want ActionType
+	}{
+		{`"attack"`, Attack},
+		{`"DEFENCE"`, Defence},
+		{`1`, Defence},
+		{`"1"`, Defence},
+	} {
+		var at ActionType
+		if err := json.Unmarshal([]byte(tc.in), &at); err != nil || at != tc.want {
+			t.Errorf("%s: unexpected action type: %v, %v", tc.in, at, err)
+		}
+	}
+	var at ActionType
+	if err := json.Unmarshal([]byte(`"Heal"`), &at); err == nil || err.Error() != `invalid action type: "Heal"` {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package core
+
+import "errors"
+
+// Match is a series of independent games.
+// The first player who wins WinsToClinch games wins the match.
+type Match struct {
+	Settings     *GameSettings `json:"settings"`
+	WinsToClinch int           `json:"winsToClinch"`
+	Games        []*Game       `json:"games"`
+}
+
+// NewMatch creates a match without games. Call StartNextGame to start one.
+func NewMatch(settings *GameSettings, winsToClinch int) *Match {
+	return &Match{
+		Settings:     settings,
+		WinsToClinch: winsToClinch,
+		Games:        make([]*Game, 0),
+	}
+}
+
+// CurrentGame returns the last started game, or nil if no game was started.
+func (m *Match) CurrentGame() *Game {
+	if len(m.Games) == 0 {
+		return nil
+	}
+	return m.Games[len(m.Games)-1]
+}
+
+// StartNextGame starts a new game after the current game is over.
+func (m *Match) StartNextGame() (*Game, error) {
+	if m.IsOver() {
+		return nil, errors.New("match was over")
+	}
+	if g := m.CurrentGame(); g != nil && !g.IsGameOver() {
+		return nil, errors.New("current game is not over")
+	}
+	g := NewGame(m.Settings)
+	m.Games = append(m.Games, g)
+	return g, nil
+}
+
+// Standings returns the number of games each player won.
+func (m *Match) Standings() map[PlayerID]int {
+	r := make(map[PlayerID]int, len(m.Settings.Players))
+	for _, p := range m.Settings.Players {
+		r[p.ID] = 0
+	}
+	for _, g := range m.Games {
+		if p, ok := g.GetWinner(); ok {
+			r[p.ID]++
+		}
+	}
+	return r
+}
+
+// Winner returns the player who clinched the match.
+func (m *Match) Winner() (*Player, bool) {
+	for id, wins := range m.Standings() {
+		if wins >= m.WinsToClinch {
+			return m.Settings.Players.Get(id)
+		}
+	}
+	return nil, false
+}
+
+func (m *Match) IsOver() bool {
+	_, ok := m.Winner()
+	return ok
+}
+
+// Rematch returns a new game with the same seed where the order of the players
+// and their InitialPoints are reversed, so the previously last player goes
+// first and the handicaps are swapped. Previous of the new game is g.
+func (g *Game) Rematch() *Game {
+	settings := g.Settings.Clone()
+	n := len(settings.Players)
+	for i := 0; i < n/2; i++ {
+		settings.Players[i], settings.Players[n-1-i] = settings.Players[n-1-i], settings.Players[i]
+	}
+	if g.Settings.InitialPoints != nil {
+		settings.InitialPoints = make(map[PlayerID]int32, len(g.Settings.InitialPoints))
+		for i, p := range g.Settings.Players {
+			if points, found := g.Settings.InitialPoints[p.ID]; found {
+				settings.InitialPoints[g.Settings.Players[n-1-i].ID] = points
+			}
+		}
+	}
+	r := NewGameWithSeed(settings, g.Seed)
+	r.Previous = g
+	return r
+}
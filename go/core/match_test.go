@@ -0,0 +1,57 @@
+package core
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	m := NewMatch(newTestSettings(), 2)
+	for i := 0; i < 2; i++ {
+		g, err := m.StartNextGame()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := m.StartNextGame(); err == nil {
+			t.Fatal("started a game before the current one is over")
+		}
+		if err := g.Timeout(2); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if s := m.Standings(); s[1] != 2 || s[2] != 0 {
+		t.Fatalf("unexpected standings: %v", s)
+	}
+	if p, ok := m.Winner(); !ok || p.ID != 1 || !m.IsOver() {
+		t.Fatalf("unexpected winner: %v, %v", p, ok)
+	}
+	if _, err := m.StartNextGame(); err == nil {
+		t.Fatal("started a game after the match is over")
+	}
+	if len(m.Games) != 2 {
+		t.Fatalf("unexpected number of games: %d", len(m.Games))
+	}
+}
+
+func TestRematch(t *testing.T) {
+	settings := newTestSettings()
+	settings.InitialPoints = map[PlayerID]int32{1: 3}
+	g := NewGame(settings)
+	if err := g.Timeout(2); err != nil {
+		t.Fatal(err)
+	}
+	r := g.Rematch()
+	if r.Previous != g || r.State.GameNum != 1 || len(r.ActionLogs) != 0 || r.IsGameOver() {
+		t.Fatalf("unexpected rematch: %+v", r)
+	}
+	if r.Settings.Players[0].ID != 2 || r.Settings.Players[1].ID != 1 {
+		t.Errorf("player order should be reversed: %v, %v", r.Settings.Players[0], r.Settings.Players[1])
+	}
+	for _, ps := range r.State.PlayerStates {
+		if ps.Status != Playing || len(ps.Actions) != 6 || ps.ThinkingTime != settings.InitialThinkingTime {
+			t.Errorf("unexpected state: %+v", ps)
+		}
+	}
+	// Player 2 comes first and takes over the handicap of player 1.
+	assertPoints(t, r, 3, 0)
+	if g.Settings.Players[0].ID != 1 || g.Settings.InitialPoints[1] != 3 {
+		t.Error("original settings were mutated")
+	}
+}
@@ -0,0 +1,48 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type migration struct {
+	to string
+	fn func(*GameSettings)
+}
+
+var migrations = map[string]migration{
+	// 0.2.0 only added settings whose zero values keep the rules of 0.1.0.
+	"0.1.0": {to: "0.2.0", fn: func(*GameSettings) {}},
+}
+
+// RegisterMigration registers fn which upgrades settings of version from to
+// version to. It replaces the migration from the same version if any.
+// It is not safe for concurrent use, so call it on initialization.
+func RegisterMigration(from, to string, fn func(*GameSettings)) {
+	migrations[from] = migration{to: to, fn: fn}
+}
+
+// MigrateSettings decodes JSON settings of any version which can be upgraded to
+// Version by the registered migrations.
+func MigrateSettings(raw []byte) (*GameSettings, error) {
+	var s GameSettings
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, err
+	}
+	if err := migrateSettings(&s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func migrateSettings(s *GameSettings) error {
+	for steps := 0; s.Version != Version; steps++ {
+		m, found := migrations[s.Version]
+		if !found || steps >= len(migrations) {
+			return fmt.Errorf("unknown version: %q", s.Version)
+		}
+		m.fn(s)
+		s.Version = m.to
+	}
+	return nil
+}
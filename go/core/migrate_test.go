@@ -0,0 +1,54 @@
+package core
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const settingsV010 = `{
+	"version": "0.1.0",
+	"players": [{"id": 1, "name": "P1"}, {"id": 2, "name": "P2"}],
+	"TotalGames": 2,
+	"initialThinkingTime": 10000000000,
+	"thinkingTimeIncrement": 5000000000,
+	"actions": [{"Type": 0, "Level": 1}, {"Type": 1, "Level": 1}],
+	"justGuardPoint": 3
+}`
+
+func TestMigrateSettings(t *testing.T) {
+	s, err := MigrateSettings([]byte(settingsV010))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Version != Version || len(s.Players) != 2 || s.TotalGames != 2 ||
+		s.InitialThinkingTime != 10*time.Second || s.ThinkingTimeIncrement != 5*time.Second ||
+		!s.Actions.Equal(ActionList{{Attack, 1}, {Defence, 1}}) || s.JustGuardPoint != 3 {
+		t.Fatalf("unexpected settings: %+v", s)
+	}
+	if err := s.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRegisterMigration(t *testing.T) {
+	RegisterMigration("test-0", "0.1.0", func(s *GameSettings) { s.JustGuardPoint *= 2 })
+	defer delete(migrations, "test-0")
+	s, err := MigrateSettings([]byte(strings.Replace(settingsV010, `"0.1.0"`, `"test-0"`, 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Version != Version || s.JustGuardPoint != 6 {
+		t.Errorf("unexpected settings: %+v", s)
+	}
+	if _, err := MigrateSettings([]byte(`{"version":"0.0.0"}`)); err == nil {
+		t.Error("unknown version should not be migrated")
+	}
+	RegisterMigration("test-1", "test-2", func(*GameSettings) {})
+	RegisterMigration("test-2", "test-1", func(*GameSettings) {})
+	defer delete(migrations, "test-1")
+	defer delete(migrations, "test-2")
+	if _, err := MigrateSettings([]byte(`{"version":"test-1"}`)); err == nil {
+		t.Error("cyclic migrations should fail")
+	}
+}
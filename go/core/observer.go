@@ -0,0 +1,41 @@
+package core
+
+// Observer is notified about state transitions of a Game.
+// Every method is called after the new state is committed to the game.
+type Observer interface {
+	OnPointsChanged(g *Game, playerID PlayerID, delta int32)
+	// OnRoundAdvanced is called with the new GameNum.
+	OnRoundAdvanced(g *Game, gameNum uint32)
+	OnGameOver(g *Game)
+}
+
+// AddObserver registers o to be notified by the game.
+func (g *Game) AddObserver(o Observer) {
+	g.observers = append(g.observers, o)
+}
+
+// notify calls the observers with the changes from prev to the current state.
+func (g *Game) notify(prev *GameState) {
+	if len(g.observers) == 0 {
+		return
+	}
+	for _, ps := range g.State.PlayerStates {
+		pps, found := prev.PlayerStates.Get(ps.PlayerID)
+		if !found || pps.Points == ps.Points {
+			continue
+		}
+		for _, o := range g.observers {
+			o.OnPointsChanged(g, ps.PlayerID, ps.Points-pps.Points)
+		}
+	}
+	if prev.GameNum == g.State.GameNum {
+		return
+	}
+	for _, o := range g.observers {
+		if g.State.IsGameOver() {
+			o.OnGameOver(g)
+		} else {
+			o.OnRoundAdvanced(g, g.State.GameNum)
+		}
+	}
+}
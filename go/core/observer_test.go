@@ -0,0 +1,78 @@
+package core
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type recordingObserver struct {
+	events []string
+}
+
+func (o *recordingObserver) OnPointsChanged(g *Game, playerID PlayerID, delta int32) {
+	ps, _ := g.State.PlayerStates.Get(playerID)
+	o.events = append(o.events, fmt.Sprintf("points %d %+d = %d", playerID, delta, ps.Points))
+}
+
+func (o *recordingObserver) OnRoundAdvanced(g *Game, gameNum uint32) {
+	o.events = append(o.events, fmt.Sprintf("round %d", gameNum))
+}
+
+func (o *recordingObserver) OnGameOver(g *Game) {
+	o.events = append(o.events, fmt.Sprintf("game over %d", len(g.ActionLogs)))
+}
+
+func TestObserver(t *testing.T) {
+	settings := newTestSettings()
+	settings.TotalGames = 2
+	settings.Actions = ActionList{{Attack, 2}, {Defence, 2}}
+	g := NewGame(settings)
+	o1, o2 := &recordingObserver{}, &recordingObserver{}
+	g.AddObserver(o1)
+	g.AddObserver(o2)
+	for _, pas := range []PlayerActionSet{
+		{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 2}},
+			{PlayerID: 2, TargetPlayerID: 1, Action: Action{Attack, 2}},
+		},
+		{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Defence, 2}},
+			{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 2}},
+		},
+		{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 2}},
+			{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 2}},
+		},
+		{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Defence, 2}},
+			{PlayerID: 2, TargetPlayerID: 1, Action: Action{Attack, 2}},
+		},
+	} {
+		if err := g.ApplyPlayerAction(pas); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []string{
+		"points 1 +2 = 2",
+		"points 2 +2 = 2",
+		"round 2",
+		"points 2 +3 = 5",
+		"points 1 +3 = 5",
+		"game over 4",
+	}
+	if !reflect.DeepEqual(o1.events, want) || !reflect.DeepEqual(o2.events, want) {
+		t.Fatalf("unexpected events: %q, %q", o1.events, o2.events)
+	}
+}
+
+func TestNoObserver(t *testing.T) {
+	g := NewGame(newTestSettings())
+	err := g.ApplyPlayerAction(PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 2}},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Attack, 2}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,141 @@
+package core
+
+import (
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+)
+
+// Save writes the whole game as JSON to w.
+func (g *Game) Save(w io.Writer) error {
+	return json.NewEncoder(w).Encode(g)
+}
+
+// LoadGame reads a game written by Save from r.
+// Settings of older versions are migrated by the registered migrations, and
+// Rand of the loaded game is reset to its Seed.
+func LoadGame(r io.Reader) (*Game, error) {
+	var g Game
+	if err := json.NewDecoder(r).Decode(&g); err != nil {
+		return nil, err
+	}
+	if err := g.validateLoaded(); err != nil {
+		return nil, fmt.Errorf("invalid saved game: %v", err)
+	}
+	return &g, nil
+}
+
+// gobGame is the gob representation of Game without Rand and observers,
+// which cannot be encoded.
+// Gob drops pointers to zero values, so the points limits are encoded
+// separately to keep a limit of 0 apart from no limit.
+type gobGame struct {
+	Settings       *GameSettings
+	MaxPoints      gobPointsLimit
+	MinPoints      gobPointsLimit
+	ActionLogs     []PlayerActionSet
+	State          *GameState
+	Events         []GameEvent
+	PendingActions PlayerActionSet
+	Seed           int64
+}
+
+type gobPointsLimit struct {
+	Set   bool
+	Value int32
+}
+
+func newGobPointsLimit(p *int32) gobPointsLimit {
+	if p == nil {
+		return gobPointsLimit{}
+	}
+	return gobPointsLimit{Set: true, Value: *p}
+}
+
+func (l gobPointsLimit) limit() *int32 {
+	if !l.Set {
+		return nil
+	}
+	return PointsLimit(l.Value)
+}
+
+// EncodeGob writes the whole game as gob to w.
+// It is more compact than Save and suited for state sync over the network.
+func (g *Game) EncodeGob(w io.Writer) error {
+	gg := &gobGame{
+		Settings:       g.Settings,
+		ActionLogs:     g.ActionLogs,
+		State:          g.State,
+		Events:         g.Events,
+		PendingActions: g.PendingActions,
+		Seed:           g.Seed,
+	}
+	if g.Settings != nil {
+		gg.MaxPoints = newGobPointsLimit(g.Settings.MaxPoints)
+		gg.MinPoints = newGobPointsLimit(g.Settings.MinPoints)
+	}
+	return gob.NewEncoder(w).Encode(gg)
+}
+
+// DecodeGob reads a game written by EncodeGob from r.
+// Rand of the decoded game is reset to its Seed.
+func DecodeGob(r io.Reader) (*Game, error) {
+	var gg gobGame
+	if err := gob.NewDecoder(r).Decode(&gg); err != nil {
+		return nil, err
+	}
+	if gg.Settings != nil {
+		gg.Settings.MaxPoints = gg.MaxPoints.limit()
+		gg.Settings.MinPoints = gg.MinPoints.limit()
+	}
+	g := &Game{
+		Settings:       gg.Settings,
+		ActionLogs:     gg.ActionLogs,
+		State:          gg.State,
+		Events:         gg.Events,
+		PendingActions: gg.PendingActions,
+		Seed:           gg.Seed,
+	}
+	if err := g.validateLoaded(); err != nil {
+		return nil, fmt.Errorf("invalid encoded game: %v", err)
+	}
+	return g, nil
+}
+
+func (g *Game) validateLoaded() error {
+	if g.Settings == nil {
+		return errors.New("settings not found")
+	}
+	if err := migrateSettings(g.Settings); err != nil {
+		return err
+	}
+	if g.Settings.Players.HasDuplicateIDs() {
+		return errors.New("duplicate player ids")
+	}
+	if g.State == nil {
+		return errors.New("state not found")
+	}
+	if len(g.State.PlayerStates) != len(g.Settings.Players) {
+		return errors.New("player states do not match players")
+	}
+	for _, ps := range g.State.PlayerStates {
+		if _, found := g.Settings.Players.Get(ps.PlayerID); !found {
+			return fmt.Errorf("player (id: %d) not found", ps.PlayerID)
+		}
+		// Unlimited is missing in games saved by older versions.
+		if g.Settings.InitialThinkingTime == InfiniteThinkingTime {
+			ps.Unlimited = true
+		}
+	}
+	if g.ActionLogs == nil {
+		g.ActionLogs = make([]PlayerActionSet, 0)
+	}
+	if g.Events == nil {
+		g.Events = make([]GameEvent, 0)
+	}
+	g.Rand = rand.New(rand.NewSource(g.Seed))
+	return nil
+}
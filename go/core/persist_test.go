@@ -0,0 +1,149 @@
+package core
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSaveLoadGame(t *testing.T) {
+	g := NewGame(newTestSettings())
+	err := g.ApplyPlayerAction(PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 3}, ThinkingTimeConsumption: time.Second},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 1}, ThinkingTimeConsumption: 2 * time.Second},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := g.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadGame(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Defence, 2}, ThinkingTimeConsumption: 3 * time.Second},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Attack, 2}, ThinkingTimeConsumption: 4 * time.Second},
+	}
+	if err := g.ApplyPlayerAction(next); err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.ApplyPlayerAction(next); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(g.State, loaded.State) || len(loaded.ActionLogs) != 2 {
+		t.Fatalf("unexpected state: %+v, want %+v", loaded.State, g.State)
+	}
+}
+
+func TestLoadGameInvalid(t *testing.T) {
+	for _, data := range []string{
+		`{`,
+		`{"state":{"gameNum":1}}`,
+		`{"settings":{"version":"0.0.0"},"state":{"gameNum":1}}`,
+		`{"settings":{"version":"` + Version + `","players":[{"id":1}]}}`,
+		`{"settings":{"version":"` + Version + `","players":[{"id":1},{"id":1}]},"state":{"gameNum":1,"playerStates":[{"playerId":1},{"playerId":1}]}}`,
+		`{"settings":{"version":"` + Version + `","players":[{"id":1}]},"state":{"gameNum":1,"playerStates":[{"playerId":2}]}}`,
+	} {
+		if _, err := LoadGame(strings.NewReader(data)); err == nil {
+			t.Errorf("%s: invalid game loaded", data)
+		}
+	}
+}
+
+func TestEncodeDecodeGob(t *testing.T) {
+	settings := newTestSettings()
+	settings.MaxPoints = PointsLimit(10)
+	g := NewGame(settings)
+	err := g.ApplyPlayerAction(PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 3}, ThinkingTimeConsumption: time.Second},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 1}, ThinkingTimeConsumption: 2 * time.Second},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := g.EncodeGob(&buf); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := DecodeGob(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *decoded.Settings.MaxPoints != 10 || !reflect.DeepEqual(g.Events, decoded.Events) {
+		t.Fatalf("unexpected game: %+v", decoded)
+	}
+	next := PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Defence, 2}, ThinkingTimeConsumption: 3 * time.Second},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Attack, 2}, ThinkingTimeConsumption: 4 * time.Second},
+	}
+	if err := g.ApplyPlayerAction(next); err != nil {
+		t.Fatal(err)
+	}
+	if err := decoded.ApplyPlayerAction(next); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(g.State, decoded.State) || !reflect.DeepEqual(g.Events, decoded.Events) {
+		t.Fatalf("unexpected state: %+v, want %+v", decoded.State, g.State)
+	}
+}
+
+func TestEncodeDecodeGobZeroPointsLimits(t *testing.T) {
+	settings := newTestSettings()
+	settings.MaxPoints = PointsLimit(0)
+	settings.MinPoints = PointsLimit(0)
+	var buf bytes.Buffer
+	if err := NewGame(settings).EncodeGob(&buf); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := DecodeGob(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded.Settings, settings) {
+		t.Fatalf("unexpected settings: %+v, want %+v", decoded.Settings, settings)
+	}
+
+	buf.Reset()
+	if err := NewGame(newTestSettings()).EncodeGob(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if decoded, err = DecodeGob(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Settings.MaxPoints != nil || decoded.Settings.MinPoints != nil {
+		t.Errorf("unexpected limits: %v, %v", decoded.Settings.MaxPoints, decoded.Settings.MinPoints)
+	}
+}
+
+func TestDecodeGobInvalid(t *testing.T) {
+	if _, err := DecodeGob(strings.NewReader("garbage")); err == nil {
+		t.Error("invalid data decoded")
+	}
+	var buf bytes.Buffer
+	if err := (&Game{Settings: newTestSettings()}).EncodeGob(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DecodeGob(&buf); err == nil {
+		t.Error("game without state decoded")
+	}
+}
+
+func TestLoadGameMigratesSettings(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewGame(newTestSettings()).Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := strings.Replace(buf.String(), `"version":"`+Version+`"`, `"version":"0.1.0"`, 1)
+	g, err := LoadGame(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Settings.Version != Version {
+		t.Errorf("unexpected version: %q", g.Settings.Version)
+	}
+}
@@ -0,0 +1,54 @@
+package core
+
+import "sync"
+
+// SafeGame serializes the operations on a Game with a mutex so that it can be
+// used from multiple goroutines. Game itself has no locking and is meant for
+// single-threaded use.
+type SafeGame struct {
+	mu sync.Mutex
+	g  *Game
+}
+
+func NewSafeGame(g *Game) *SafeGame {
+	return &SafeGame{g: g}
+}
+
+// Do calls f with the game while holding the lock.
+// f must not retain the game after returning.
+func (s *SafeGame) Do(f func(g *Game) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return f(s.g)
+}
+
+func (s *SafeGame) ApplyPlayerAction(playerActions PlayerActionSet) error {
+	return s.Do(func(g *Game) error { return g.ApplyPlayerAction(playerActions) })
+}
+
+func (s *SafeGame) Undo() error {
+	return s.Do(func(g *Game) error { return g.Undo() })
+}
+
+func (s *SafeGame) Forfeit(playerID PlayerID) error {
+	return s.Do(func(g *Game) error { return g.Forfeit(playerID) })
+}
+
+func (s *SafeGame) Timeout(playerID PlayerID) error {
+	return s.Do(func(g *Game) error { return g.Timeout(playerID) })
+}
+
+func (s *SafeGame) SubmitAction(pa *PlayerAction) (resolved bool, err error) {
+	err = s.Do(func(g *Game) error {
+		resolved, err = g.SubmitAction(pa)
+		return err
+	})
+	return resolved, err
+}
+
+// Snapshot returns a consistent deep copy of the game.
+func (s *SafeGame) Snapshot() *Game {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.g.Clone()
+}
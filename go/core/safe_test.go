@@ -0,0 +1,48 @@
+package core
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestSafeGame(t *testing.T) {
+	settings := newTestSettings()
+	settings.Actions = ActionList{{Attack, 1}, {Attack, 2}, {Attack, 3}, {Attack, 4}}
+	sg := NewSafeGame(NewGame(settings))
+	var wg sync.WaitGroup
+	for _, a := range settings.Actions {
+		wg.Add(2)
+		a := a
+		go func() {
+			defer wg.Done()
+			err := sg.ApplyPlayerAction(PlayerActionSet{
+				{PlayerID: 1, TargetPlayerID: 2, Action: a},
+				{PlayerID: 2, TargetPlayerID: 1, Action: a},
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			sg.Snapshot()
+		}()
+	}
+	wg.Wait()
+	g := sg.Snapshot()
+	if !g.IsGameOver() || len(g.ActionLogs) != 4 {
+		t.Fatalf("unexpected game: %+v", g.State)
+	}
+	assertPoints(t, g, 10, 10)
+	g.State.PlayerStates[0].Points = 0
+	g.ActionLogs[0][0].Action.Level = 9
+	if s := sg.Snapshot(); s.State.PlayerStates[0].Points != 10 || s.ActionLogs[0][0].Action.Level == 9 {
+		t.Fatal("snapshot shares state with the game")
+	}
+	if err := sg.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if err := sg.Forfeit(1); err != nil {
+		t.Fatal(err)
+	}
+}
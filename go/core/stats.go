@@ -0,0 +1,115 @@
+package core
+
+import "time"
+
+// Stats accumulates statistics over finished games for tuning the balance.
+// The zero value is ready to use.
+type Stats struct {
+	games           int
+	levelAttacks    map[ActionLevel]int
+	levelPoints     map[ActionLevel]int32
+	attacks         int
+	justGuards      int
+	actions         int
+	thinkingTime    time.Duration
+	attackerWins    int
+	attackerDecided int
+}
+
+// StatsReport is the aggregates of Stats.
+type StatsReport struct {
+	Games int
+	// PointsPerLevel is the average points which an attack of each level
+	// scored for the attacker.
+	PointsPerLevel map[ActionLevel]float64
+	// JustGuardRate is the ratio of just guards to attacks.
+	JustGuardRate float64
+	// AverageThinkingTime is the average consumption per action.
+	AverageThinkingTime time.Duration
+	// AttackerWinRate is the ratio of the games won by the player who attacked
+	// the most, out of the games which had a winner and such a single player.
+	AttackerWinRate float64
+}
+
+// Record adds g to s. Games which are not over are ignored.
+// The points of attacks and just guards are derived from Events.
+func (s *Stats) Record(g *Game) {
+	if !g.IsGameOver() {
+		return
+	}
+	if s.levelAttacks == nil {
+		s.levelAttacks = make(map[ActionLevel]int)
+		s.levelPoints = make(map[ActionLevel]int32)
+	}
+	s.games++
+	attacks := make(map[PlayerID]int)
+	for turn, pas := range g.ActionLogs {
+		if pas.outOfBand() {
+			continue
+		}
+		for _, pa := range pas {
+			s.actions++
+			s.thinkingTime += pa.ThinkingTimeConsumption
+			if pa.Action.Type != Attack {
+				continue
+			}
+			attacks[pa.PlayerID]++
+			s.attacks++
+			s.levelAttacks[pa.Action.Level]++
+			for _, e := range g.Events {
+				if e.Turn != turn || e.Type != PointsAwardedEvent {
+					continue
+				}
+				if e.PlayerID == pa.PlayerID && e.TargetPlayerID == pa.TargetPlayerID {
+					s.levelPoints[pa.Action.Level] += e.Delta
+				}
+			}
+		}
+	}
+	for _, e := range g.Events {
+		if e.Type == JustGuardEvent {
+			s.justGuards++
+		}
+	}
+	winner, ok := g.GetWinner()
+	if !ok {
+		return
+	}
+	var attacker PlayerID
+	most, single := 0, false
+	for id, n := range attacks {
+		if n > most {
+			attacker, most, single = id, n, true
+		} else if n == most {
+			single = false
+		}
+	}
+	if !single {
+		return
+	}
+	s.attackerDecided++
+	if attacker == winner.ID {
+		s.attackerWins++
+	}
+}
+
+// Report returns the aggregates of the recorded games.
+func (s *Stats) Report() StatsReport {
+	r := StatsReport{
+		Games:          s.games,
+		PointsPerLevel: make(map[ActionLevel]float64, len(s.levelAttacks)),
+	}
+	for level, n := range s.levelAttacks {
+		r.PointsPerLevel[level] = float64(s.levelPoints[level]) / float64(n)
+	}
+	if s.attacks > 0 {
+		r.JustGuardRate = float64(s.justGuards) / float64(s.attacks)
+	}
+	if s.actions > 0 {
+		r.AverageThinkingTime = s.thinkingTime / time.Duration(s.actions)
+	}
+	if s.attackerDecided > 0 {
+		r.AttackerWinRate = float64(s.attackerWins) / float64(s.attackerDecided)
+	}
+	return r
+}
@@ -0,0 +1,54 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	settings := newTestSettings()
+	settings.PlayerActions = map[PlayerID]ActionList{
+		1: {{Attack, 1}, {Attack, 2}, {Defence, 1}},
+		2: {{Attack, 1}, {Defence, 1}, {Defence, 2}},
+	}
+	g := NewGame(settings)
+	for _, pas := range []PlayerActionSet{
+		{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 2}, ThinkingTimeConsumption: time.Second},
+			{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 1}, ThinkingTimeConsumption: time.Second},
+		},
+		{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 1}, ThinkingTimeConsumption: time.Second},
+			{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 2}, ThinkingTimeConsumption: time.Second},
+		},
+		{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Defence, 1}, ThinkingTimeConsumption: 2 * time.Second},
+			{PlayerID: 2, TargetPlayerID: 1, Action: Action{Attack, 1}},
+		},
+	} {
+		if err := g.ApplyPlayerAction(pas); err != nil {
+			t.Fatal(err)
+		}
+	}
+	forfeited := NewGame(newTestSettings())
+	if err := forfeited.Forfeit(1); err != nil {
+		t.Fatal(err)
+	}
+
+	var s Stats
+	s.Record(g)
+	s.Record(forfeited)
+	s.Record(NewGame(newTestSettings()))
+	r := s.Report()
+	want := StatsReport{
+		Games:               2,
+		PointsPerLevel:      map[ActionLevel]float64{1: 0, 2: 1},
+		JustGuardRate:       1.0 / 3,
+		AverageThinkingTime: time.Second,
+		AttackerWinRate:     1,
+	}
+	if !reflect.DeepEqual(r, want) {
+		t.Errorf("unexpected report: %+v, want %+v", r, want)
+	}
+}
@@ -0,0 +1,69 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Store is the contract of persistence backends of games identified by id.
+// LoadGame and DeleteGame return an error matching ErrGameNotFound for unknown
+// ids.
+type Store interface {
+	SaveGame(ctx context.Context, id string, g *Game) error
+	LoadGame(ctx context.Context, id string) (*Game, error)
+	DeleteGame(ctx context.Context, id string) error
+}
+
+// MemoryStore is the in-memory reference implementation of Store.
+// Games are kept as written by Game.Save, so the loaded games share nothing
+// with the saved ones and lose ScoreFunc and ComboBonus as with other
+// backends. The zero value is ready to use.
+type MemoryStore struct {
+	mu    sync.Mutex
+	games map[string][]byte
+}
+
+func (s *MemoryStore) SaveGame(ctx context.Context, id string, g *Game) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := g.Save(&buf); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.games == nil {
+		s.games = make(map[string][]byte)
+	}
+	s.games[id] = buf.Bytes()
+	return nil
+}
+
+func (s *MemoryStore) LoadGame(ctx context.Context, id string) (*Game, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	data, found := s.games[id]
+	s.mu.Unlock()
+	if !found {
+		return nil, fmt.Errorf("game (id: %q): %w", id, ErrGameNotFound)
+	}
+	return LoadGame(bytes.NewReader(data))
+}
+
+func (s *MemoryStore) DeleteGame(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, found := s.games[id]; !found {
+		return fmt.Errorf("game (id: %q): %w", id, ErrGameNotFound)
+	}
+	delete(s.games, id)
+	return nil
+}
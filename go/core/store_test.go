@@ -0,0 +1,51 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestMemoryStore(t *testing.T) {
+	var s Store = &MemoryStore{}
+	ctx := context.Background()
+	if _, err := s.LoadGame(ctx, "g1"); !errors.Is(err, ErrGameNotFound) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	g := NewGame(newTestSettings())
+	err := g.ApplyPlayerAction(PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 3}},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 1}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveGame(ctx, "g1", g); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := s.LoadGame(ctx, "g1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.Equal(g) || loaded == g {
+		t.Fatalf("unexpected game: %v", loaded.Diff(g))
+	}
+	loaded.State.PlayerStates[0].Points = 9
+	if again, _ := s.LoadGame(ctx, "g1"); again.State.PlayerStates[0].Points != 2 {
+		t.Error("stored game was mutated")
+	}
+	if err := s.DeleteGame(ctx, "g1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.LoadGame(ctx, "g1"); !errors.Is(err, ErrGameNotFound) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := s.DeleteGame(ctx, "g1"); !errors.Is(err, ErrGameNotFound) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := s.SaveGame(cancelled, "g2", g); !errors.Is(err, context.Canceled) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,151 @@
+package core
+
+import (
+	"fmt"
+	"time"
+)
+
+// SubmitAction buffers the action of a player in PendingActions. Once every
+// player submitted, the buffered actions are applied by ApplyPlayerAction and
+// resolved is true. The buffer is cleared even if applying fails so that the
+// players can submit again. Only the acting players can submit.
+func (g *Game) SubmitAction(pa *PlayerAction) (resolved bool, err error) {
+	if g.IsGameOver() {
+		return false, ErrGameOver
+	}
+	if _, acts := g.actingPlayers(g.State).Get(pa.PlayerID); !acts {
+		if ps, found := g.State.PlayerStates.Get(pa.PlayerID); found && ps.Status == Eliminated {
+			return false, fmt.Errorf("player (id: %d): %w", pa.PlayerID, ErrPlayerEliminated)
+		}
+		return false, &PlayerNotFoundError{PlayerID: pa.PlayerID}
+	}
+	if _, found := g.PendingActions.Get(pa.PlayerID); found {
+		return false, fmt.Errorf("player (id: %d) already submitted", pa.PlayerID)
+	}
+	if err := g.ValidateActions(PlayerActionSet{pa}); err != nil {
+		return false, err
+	}
+	ps, _ := g.State.PlayerStates.Get(pa.PlayerID)
+	if err := g.Settings.checkMinConsumption(ps, pa.ThinkingTimeConsumption); err != nil {
+		return false, err
+	}
+	if err := g.Settings.consumeThinkingTime(ps.Clone(), pa.ThinkingTimeConsumption); err != nil {
+		return false, err
+	}
+	g.PendingActions = append(g.PendingActions, pa)
+	if len(g.PendingPlayers()) > 0 {
+		return false, nil
+	}
+	pending := g.PendingActions
+	g.PendingActions = nil
+	if err := g.ApplyPlayerAction(pending); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// PendingPlayers returns the players who have not submitted their actions yet.
+func (g *Game) PendingPlayers() []PlayerID {
+	players := g.actingPlayers(g.State)
+	r := make([]PlayerID, 0, len(players))
+	for _, p := range players {
+		if _, found := g.PendingActions.Get(p.ID); !found {
+			r = append(r, p.ID)
+		}
+	}
+	return r
+}
+
+// CurrentRoundActions returns a copy of the actions submitted by SubmitAction
+// which are not resolved yet.
+func (g *Game) CurrentRoundActions() PlayerActionSet {
+	return g.CurrentRoundActionsFor(nil)
+}
+
+// CurrentRoundActionsFor is like CurrentRoundActions but, as SpectatorView,
+// includes only the action of forPlayer unless forPlayer is nil.
+// Use PendingPlayers to see who else has submitted.
+func (g *Game) CurrentRoundActionsFor(forPlayer *PlayerID) PlayerActionSet {
+	r := make(PlayerActionSet, 0, len(g.PendingActions))
+	for _, pa := range g.PendingActions {
+		if forPlayer == nil || pa.PlayerID == *forPlayer {
+			copied := *pa
+			r = append(r, &copied)
+		}
+	}
+	return r
+}
+
+// AutoResolveTimeouts submits the action by defaultFor for each pending player
+// whose clock would expire after elapsed since the round started, charging the
+// most the clock allows: the remaining thinking time and all the byo-yomi
+// periods but the last one, which the player keeps. Unlimited clocks never
+// expire.
+func (g *Game) AutoResolveTimeouts(elapsed time.Duration, defaultFor func(PlayerID) Action) error {
+	if g.IsGameOver() {
+		return ErrGameOver
+	}
+	for _, id := range g.PendingPlayers() {
+		ps, found := g.State.PlayerStates.Get(id)
+		if !found {
+			return &PlayerNotFoundError{PlayerID: id}
+		}
+		if g.Settings.consumeThinkingTime(ps.Clone(), elapsed) == nil {
+			continue
+		}
+		target := id
+		if targets := g.targets(id); len(targets) > 0 {
+			target = targets[0]
+		}
+		_, err := g.SubmitAction(&PlayerAction{
+			PlayerID:                id,
+			TargetPlayerID:          target,
+			Action:                  defaultFor(id),
+			ThinkingTimeConsumption: ps.ThinkingTime + time.Duration(ps.ByoYomiPeriods)*g.Settings.ByoYomiPeriodLength,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PlayerGameView is everything a client of a player needs to rebuild its view,
+// e.g. to resume after reconnecting.
+type PlayerGameView struct {
+	PlayerID PlayerID      `json:"playerId"`
+	Settings *GameSettings `json:"settings"`
+	// State is redacted for the player as SpectatorView.
+	State *GameState `json:"state"`
+	// Actions is the remaining actions of the player.
+	Actions ActionList `json:"actions"`
+	// Submitted is the action submitted by SubmitAction and not resolved yet.
+	Submitted *PlayerAction `json:"submitted,omitempty"`
+	// MustSubmit is true if the game waits for the action of the player.
+	MustSubmit bool `json:"mustSubmit"`
+}
+
+// PlayerSnapshot returns the view of playerID.
+func (g *Game) PlayerSnapshot(playerID PlayerID) (*PlayerGameView, error) {
+	ps, found := g.State.PlayerStates.Get(playerID)
+	if !found {
+		return nil, &PlayerNotFoundError{PlayerID: playerID}
+	}
+	v := &PlayerGameView{
+		PlayerID: playerID,
+		Settings: g.Settings.Clone(),
+		State:    g.SpectatorView(&playerID),
+		Actions:  ps.AvailableActions().Clone(),
+	}
+	if submitted := g.CurrentRoundActionsFor(&playerID); len(submitted) > 0 {
+		v.Submitted = submitted[0]
+	}
+	if !g.IsGameOver() {
+		for _, id := range g.PendingPlayers() {
+			if id == playerID {
+				v.MustSubmit = true
+			}
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,148 @@
+package core
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSubmitActionEliminated(t *testing.T) {
+	settings := newTestSettings()
+	settings.Players = append(settings.Players, &Player{ID: 3, Name: "P3"})
+	g := NewGame(settings)
+	if err := g.Eliminate(3); err != nil {
+		t.Fatal(err)
+	}
+	_, err := g.SubmitAction(&PlayerAction{PlayerID: 3, TargetPlayerID: 1, Action: Action{Attack, 1}})
+	if !errors.Is(err, ErrPlayerEliminated) || len(g.PendingActions) != 0 {
+		t.Fatalf("unexpected result: %v, %v", err, g.PendingActions)
+	}
+	if _, err := g.SubmitAction(&PlayerAction{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 1}}); err != nil {
+		t.Fatal(err)
+	}
+	resolved, err := g.SubmitAction(&PlayerAction{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 1}})
+	if err != nil || !resolved || len(g.ActionLogs) != 2 {
+		t.Fatalf("unexpected result: %v, %v, %v", resolved, err, g.ActionLogs)
+	}
+}
+
+func TestSubmitAction(t *testing.T) {
+	g := NewGame(newTestSettings())
+	if !reflect.DeepEqual(g.PendingPlayers(), []PlayerID{1, 2}) {
+		t.Fatalf("unexpected pending players: %v", g.PendingPlayers())
+	}
+	for i, pa := range []*PlayerAction{
+		{PlayerID: 3, TargetPlayerID: 1, Action: Action{Attack, 1}},
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 4}},
+		{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 1}, ThinkingTimeConsumption: time.Hour},
+	} {
+		if _, err := g.SubmitAction(pa); err == nil {
+			t.Errorf("%d: invalid action submitted", i)
+		}
+	}
+	resolved, err := g.SubmitAction(&PlayerAction{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 3}})
+	if err != nil || resolved {
+		t.Fatalf("unexpected result: %v, %v", resolved, err)
+	}
+	if _, err := g.SubmitAction(&PlayerAction{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 1}}); err == nil {
+		t.Fatal("resubmitted")
+	}
+	if !reflect.DeepEqual(g.PendingPlayers(), []PlayerID{2}) || len(g.ActionLogs) != 0 {
+		t.Fatalf("unexpected pending players: %v", g.PendingPlayers())
+	}
+	resolved, err = g.SubmitAction(&PlayerAction{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 1}})
+	if err != nil || !resolved {
+		t.Fatalf("unexpected result: %v, %v", resolved, err)
+	}
+	if len(g.ActionLogs) != 1 || len(g.PendingActions) != 0 || len(g.PendingPlayers()) != 2 {
+		t.Fatalf("actions were not resolved: %+v", g.ActionLogs)
+	}
+	assertPoints(t, g, 2, 0)
+}
+
+func TestCurrentRoundActions(t *testing.T) {
+	g := NewGame(newTestSettings())
+	pa := &PlayerAction{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 3}}
+	if _, err := g.SubmitAction(pa); err != nil {
+		t.Fatal(err)
+	}
+	if pas := g.CurrentRoundActions(); len(pas) != 1 || !reflect.DeepEqual(pas[0], pa) {
+		t.Fatalf("unexpected actions: %v", pas)
+	}
+	p1, p2 := PlayerID(1), PlayerID(2)
+	if pas := g.CurrentRoundActionsFor(&p1); len(pas) != 1 {
+		t.Errorf("own action should be visible: %v", pas)
+	}
+	if pas := g.CurrentRoundActionsFor(&p2); len(pas) != 0 {
+		t.Errorf("opponent action should be redacted: %v", pas)
+	}
+	if _, err := g.SubmitAction(&PlayerAction{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 1}}); err != nil {
+		t.Fatal(err)
+	}
+	if pas := g.CurrentRoundActions(); len(pas) != 0 {
+		t.Errorf("actions should be cleared after resolution: %v", pas)
+	}
+}
+
+func TestAutoResolveTimeouts(t *testing.T) {
+	g := NewGame(newTestSettings())
+	defence := func(PlayerID) Action { return Action{Defence, 1} }
+	if _, err := g.SubmitAction(&PlayerAction{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 2}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.AutoResolveTimeouts(5*time.Second, defence); err != nil {
+		t.Fatal(err)
+	}
+	if len(g.ActionLogs) != 0 || len(g.PendingActions) != 1 {
+		t.Fatal("player with time left should not be resolved")
+	}
+	if err := g.AutoResolveTimeouts(time.Minute, defence); err != nil {
+		t.Fatal(err)
+	}
+	if len(g.ActionLogs) != 1 || len(g.PendingActions) != 0 {
+		t.Fatalf("unexpected logs: %v", g.ActionLogs)
+	}
+	if pa, _ := g.ActionLogs[0].Get(2); pa.Action != (Action{Defence, 1}) || pa.ThinkingTimeConsumption != 10*time.Second {
+		t.Errorf("unexpected default action: %v", pa)
+	}
+	assertPoints(t, g, 1, 0)
+
+	settings := newTestSettings()
+	settings.ByoYomiPeriods = 3
+	settings.ByoYomiPeriodLength = 5 * time.Second
+	g = NewGame(settings)
+	if _, err := g.SubmitAction(&PlayerAction{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 2}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.AutoResolveTimeouts(time.Minute, defence); err != nil {
+		t.Fatal(err)
+	}
+	if ps, _ := g.State.PlayerStates.Get(2); len(g.ActionLogs) != 1 || ps.ThinkingTime != 0 || ps.ByoYomiPeriods != 1 {
+		t.Errorf("unexpected clock: %+v", ps)
+	}
+}
+
+func TestPlayerSnapshot(t *testing.T) {
+	g := NewGame(newTestSettings())
+	if _, err := g.PlayerSnapshot(3); !errors.Is(err, ErrPlayerNotFound) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pa := &PlayerAction{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 3}}
+	if _, err := g.SubmitAction(pa); err != nil {
+		t.Fatal(err)
+	}
+	v, err := g.PlayerSnapshot(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.MustSubmit || v.Submitted == nil || *v.Submitted != *pa || len(v.Actions) != 6 {
+		t.Errorf("unexpected view: %+v", v)
+	}
+	if ps, _ := v.State.PlayerStates.Get(2); len(ps.Actions) != 0 {
+		t.Errorf("actions of the opponent should be redacted: %v", ps.Actions)
+	}
+	if v, _ := g.PlayerSnapshot(2); !v.MustSubmit || v.Submitted != nil {
+		t.Errorf("unexpected view: %+v", v)
+	}
+}
@@ -0,0 +1,71 @@
+package core
+
+// isTeammate returns true if a and b are different players in the same team.
+func (s *GameSettings) isTeammate(a, b PlayerID) bool {
+	ta, foundA := s.Teams[a]
+	tb, foundB := s.Teams[b]
+	return a != b && foundA && foundB && ta == tb
+}
+
+// TeamScores returns the sum of the points of the members of each team.
+func (g *Game) TeamScores() map[int]int32 {
+	r := make(map[int]int32)
+	if g.State == nil {
+		return r
+	}
+	for _, ps := range g.State.PlayerStates {
+		if team, found := g.Settings.Teams[ps.PlayerID]; found {
+			r[team] += ps.Points
+		}
+	}
+	return r
+}
+
+// GetWinningTeam is the team version of GetWinner.
+// A team with a member who timed out or forfeited cannot win, nor can a team
+// whose members were all eliminated.
+func (g *Game) GetWinningTeam() (int, bool) {
+	if !g.IsGameOver() || len(g.Settings.Teams) == 0 {
+		return 0, false
+	}
+	lost := make(map[int]bool)
+	playing := make(map[int]bool)
+	for _, ps := range g.State.PlayerStates {
+		team, found := g.Settings.Teams[ps.PlayerID]
+		if !found {
+			continue
+		}
+		switch ps.Status {
+		case Playing:
+			playing[team] = true
+		case TimedOut, Forfeited:
+			lost[team] = true
+		}
+	}
+	for team := range g.TeamScores() {
+		if !playing[team] {
+			lost[team] = true
+		}
+	}
+	scores := g.TeamScores()
+	winner, found, draw := 0, false, false
+	for team, points := range scores {
+		if lost[team] {
+			continue
+		}
+		if !found {
+			winner, found = team, true
+			continue
+		}
+		switch c := g.Settings.comparePoints(points, scores[winner]); {
+		case c > 0:
+			winner, draw = team, false
+		case c == 0:
+			draw = true
+		}
+	}
+	if !found || draw {
+		return 0, false
+	}
+	return winner, true
+}
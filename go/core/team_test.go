@@ -0,0 +1,86 @@
+package core
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func newTestTeamSettings() *GameSettings {
+	settings := newTestSettings()
+	settings.Players = append(settings.Players, &Player{ID: 3, Name: "P3"}, &Player{ID: 4, Name: "P4"})
+	settings.Actions = ActionList{{Attack, 1}, {Attack, 2}}
+	settings.Teams = map[PlayerID]int{1: 1, 2: 2, 3: 1, 4: 2}
+	return settings
+}
+
+func TestTeamScores(t *testing.T) {
+	g := NewGame(newTestTeamSettings())
+	for _, pas := range []PlayerActionSet{
+		{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 2}},
+			{PlayerID: 2, TargetPlayerID: 1, Action: Action{Attack, 1}},
+			{PlayerID: 3, TargetPlayerID: 4, Action: Action{Attack, 2}},
+			{PlayerID: 4, TargetPlayerID: 3, Action: Action{Attack, 2}},
+		},
+		{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 1}},
+			{PlayerID: 2, TargetPlayerID: 1, Action: Action{Attack, 2}},
+			{PlayerID: 3, TargetPlayerID: 4, Action: Action{Attack, 1}},
+			{PlayerID: 4, TargetPlayerID: 3, Action: Action{Attack, 1}},
+		},
+	} {
+		if _, found := g.GetWinningTeam(); found {
+			t.Fatal("winning team before game over")
+		}
+		if err := g.ApplyPlayerAction(pas); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if s := g.TeamScores(); !reflect.DeepEqual(s, map[int]int32{1: 6, 2: 6}) {
+		t.Fatalf("unexpected team scores: %v", s)
+	}
+	if _, found := g.GetWinningTeam(); found {
+		t.Error("tied teams should not win")
+	}
+	g.State.PlayerStates[3].Points++
+	if team, found := g.GetWinningTeam(); !found || team != 2 {
+		t.Errorf("unexpected winning team: %d, %v", team, found)
+	}
+	g.State.PlayerStates[3].Status = Eliminated
+	if team, found := g.GetWinningTeam(); !found || team != 2 {
+		t.Errorf("an eliminated member should not disqualify the team: %d, %v", team, found)
+	}
+	g.State.PlayerStates[1].Status = Eliminated
+	if team, found := g.GetWinningTeam(); !found || team != 1 {
+		t.Errorf("a team without active members should not win: %d, %v", team, found)
+	}
+	g.State.PlayerStates[2].Status = Forfeited
+	if team, found := g.GetWinningTeam(); found {
+		t.Errorf("a team with a forfeited member should not win: %d", team)
+	}
+}
+
+func TestFriendlyFire(t *testing.T) {
+	settings := newTestTeamSettings()
+	pas := PlayerActionSet{
+		{PlayerID: 1, TargetPlayerID: 3, Action: Action{Attack, 1}},
+		{PlayerID: 2, TargetPlayerID: 1, Action: Action{Attack, 1}},
+		{PlayerID: 3, TargetPlayerID: 4, Action: Action{Attack, 1}},
+		{PlayerID: 4, TargetPlayerID: 3, Action: Action{Attack, 1}},
+	}
+	g := NewGame(settings)
+	if err := g.ApplyPlayerAction(pas); !errors.Is(err, ErrFriendlyFire) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := g.ValidateActions(pas); !errors.Is(err, ErrFriendlyFire) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if targets := g.targets(1); !reflect.DeepEqual(targets, []PlayerID{2, 4}) {
+		t.Errorf("unexpected targets: %v", targets)
+	}
+	settings.FriendlyFire = true
+	if err := g.ApplyPlayerAction(pas); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,87 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// TimelineEntry is the playback of an action set in ActionLogs.
+type TimelineEntry struct {
+	Turn int `json:"turn"`
+	// GameNum is the game in which the action set was played.
+	GameNum uint32          `json:"gameNum"`
+	Actions PlayerActionSet `json:"actions"`
+	// PointDeltas and ThinkingTimeDeltas are the changes of the players by
+	// the action set.
+	PointDeltas        map[PlayerID]int32         `json:"pointDeltas"`
+	ThinkingTimeDeltas map[PlayerID]time.Duration `json:"-"`
+	JustGuard          bool                       `json:"justGuard,omitempty"`
+	RoundAdvanced      bool                       `json:"roundAdvanced,omitempty"`
+	GameOver           bool                       `json:"gameOver,omitempty"`
+}
+
+func (e TimelineEntry) MarshalJSON() ([]byte, error) {
+	type alias TimelineEntry
+	ms := make(map[PlayerID]int64, len(e.ThinkingTimeDeltas))
+	for id, d := range e.ThinkingTimeDeltas {
+		ms[id] = d.Milliseconds()
+	}
+	return json.Marshal(&struct {
+		alias
+		ThinkingTimeDeltasMillis map[PlayerID]int64 `json:"thinkingTimeDeltasMs"`
+	}{alias(e), ms})
+}
+
+// Timeline returns an entry per action set of ActionLogs, derived by replaying
+// them. Flags are taken from Events of each turn.
+func (g *Game) Timeline() ([]TimelineEntry, error) {
+	r := make([]TimelineEntry, 0, len(g.ActionLogs))
+	state := NewGameState(g.Settings)
+	for i, pas := range g.ActionLogs {
+		next, _, err := g.nextState(state, pas)
+		if err != nil {
+			return nil, fmt.Errorf("action log %d: %w", i, err)
+		}
+		e := TimelineEntry{
+			Turn:               i,
+			GameNum:            state.GameNum,
+			Actions:            pas,
+			PointDeltas:        make(map[PlayerID]int32, len(next.PlayerStates)),
+			ThinkingTimeDeltas: make(map[PlayerID]time.Duration, len(next.PlayerStates)),
+		}
+		for _, ps := range next.PlayerStates {
+			prev, found := state.PlayerStates.Get(ps.PlayerID)
+			if !found {
+				return nil, &PlayerNotFoundError{PlayerID: ps.PlayerID}
+			}
+			e.PointDeltas[ps.PlayerID] = ps.Points - prev.Points
+			e.ThinkingTimeDeltas[ps.PlayerID] = ps.ThinkingTime - prev.ThinkingTime
+		}
+		for _, ev := range g.Events {
+			if ev.Turn != i {
+				continue
+			}
+			switch ev.Type {
+			case JustGuardEvent:
+				e.JustGuard = true
+			case RoundAdvancedEvent:
+				e.RoundAdvanced = true
+			case GameOverEvent:
+				e.GameOver = true
+			}
+		}
+		r = append(r, e)
+		state = next
+	}
+	return r, nil
+}
+
+// TimelineJSON returns Timeline as a JSON array for animating the playback.
+func (g *Game) TimelineJSON() ([]byte, error) {
+	timeline, err := g.Timeline()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(timeline)
+}
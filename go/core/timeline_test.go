@@ -0,0 +1,58 @@
+package core
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestTimelineJSON(t *testing.T) {
+	settings := newTestSettings()
+	settings.Actions = ActionList{{Attack, 2}, {Defence, 2}}
+	g := NewGame(settings)
+	sets := []PlayerActionSet{
+		{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Attack, 2}, ThinkingTimeConsumption: time.Second},
+			{PlayerID: 2, TargetPlayerID: 1, Action: Action{Defence, 2}, ThinkingTimeConsumption: 7 * time.Second},
+		},
+		{
+			{PlayerID: 1, TargetPlayerID: 2, Action: Action{Defence, 2}},
+			{PlayerID: 2, TargetPlayerID: 1, Action: Action{Attack, 2}},
+		},
+	}
+	if _, err := g.ApplyPlayerActions(sets); err != nil {
+		t.Fatal(err)
+	}
+	data, err := g.TimelineJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var timeline []struct {
+		Turn                 int              `json:"turn"`
+		GameNum              uint32           `json:"gameNum"`
+		Actions              []interface{}    `json:"actions"`
+		PointDeltas          map[string]int32 `json:"pointDeltas"`
+		ThinkingTimeDeltasMs map[string]int64 `json:"thinkingTimeDeltasMs"`
+		JustGuard            bool             `json:"justGuard"`
+		GameOver             bool             `json:"gameOver"`
+	}
+	if err := json.Unmarshal(data, &timeline); err != nil {
+		t.Fatal(err)
+	}
+	if len(timeline) != len(g.ActionLogs) {
+		t.Fatalf("unexpected number of rounds: %d", len(timeline))
+	}
+	first := timeline[0]
+	if first.Turn != 0 || first.GameNum != 1 || len(first.Actions) != 2 || !first.JustGuard || first.GameOver {
+		t.Errorf("unexpected entry: %+v", first)
+	}
+	if first.PointDeltas["1"] != 0 || first.PointDeltas["2"] != 3 {
+		t.Errorf("unexpected point deltas: %v", first.PointDeltas)
+	}
+	if first.ThinkingTimeDeltasMs["1"] != 4000 || first.ThinkingTimeDeltasMs["2"] != -2000 {
+		t.Errorf("unexpected clock deltas: %v", first.ThinkingTimeDeltasMs)
+	}
+	if last := timeline[1]; !last.JustGuard || !last.GameOver || last.PointDeltas["1"] != 3 {
+		t.Errorf("unexpected entry: %+v", last)
+	}
+}